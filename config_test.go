@@ -1,15 +1,33 @@
 package main
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/vanducng/cflip/internal/config"
 )
 
 const testProvider = "test"
 
+// isolateHome points HOME at a temp dir and clears the env vars that would
+// move the config elsewhere
+func isolateHome(t *testing.T) string {
+	t.Helper()
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	for _, key := range []string{config.ConfigEnvVar, "XDG_CONFIG_HOME", "XDG_STATE_HOME"} {
+		t.Setenv(key, "")
+	}
+	return home
+}
+
 func TestNewConfig(t *testing.T) {
 	cfg := config.NewConfig()
 
@@ -55,13 +73,535 @@ func TestConfigSaveLoad(t *testing.T) {
 }
 
 func TestGetConfigPath(t *testing.T) {
-	path := config.GetConfigPath()
-	if path == "" {
-		t.Error("Config path should not be empty")
-	}
+	home := isolateHome(t)
 
-	expected := filepath.Join(os.Getenv("HOME"), ".cflip", "config.toml")
-	if path != expected {
+	// macOS and Windows keep ~/.cflip; elsewhere a clean home gets the XDG path
+	expected := filepath.Join(home, ".config", "cflip", "config.toml")
+	if runtime.GOOS == "darwin" || runtime.GOOS == "windows" {
+		expected = filepath.Join(home, ".cflip", "config.toml")
+	}
+	if path := config.GetConfigPath(); path != expected {
 		t.Errorf("Expected config path '%s', got '%s'", expected, path)
 	}
 }
+
+func TestGetConfigPathLegacyFallback(t *testing.T) {
+	home := isolateHome(t)
+
+	legacyDir := filepath.Join(home, ".cflip")
+	if err := os.MkdirAll(legacyDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(legacyDir, "config.toml"), []byte("provider = \"anthropic\"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := filepath.Join(legacyDir, "config.toml")
+	if path := config.GetConfigPath(); path != expected {
+		t.Errorf("Expected the legacy config path '%s', got '%s'", expected, path)
+	}
+}
+
+func TestGenerateSettingsPreview(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.SetProviderConfig("glm", config.ProviderConfig{
+		Token:   "glm-token",
+		BaseURL: "https://api.z.ai/api/anthropic",
+		ModelMap: map[string]string{
+			"haiku":  "glm-4.5-air",
+			"sonnet": "glm-4.6",
+		},
+	})
+	cfg.SetProviderConfig("custom", config.ProviderConfig{
+		Token:   "custom-token",
+		BaseURL: "https://proxy.example.com",
+	})
+
+	tests := []struct {
+		name     string
+		provider string
+		setup    func(c *config.Config)
+		want     map[string]string
+		wantErr  bool
+	}{
+		{
+			name:     "anthropic without token",
+			provider: "anthropic",
+			want:     map[string]string{},
+		},
+		{
+			name:     "anthropic with token",
+			provider: "anthropic",
+			setup: func(c *config.Config) {
+				c.SetProviderConfig("anthropic", config.ProviderConfig{Token: "sk-ant-test"})
+			},
+			want: map[string]string{config.EnvAuthToken: "sk-ant-test"},
+		},
+		{
+			name:     "anthropic with token in subscription mode",
+			provider: "anthropic",
+			setup: func(c *config.Config) {
+				c.SetProviderConfig("anthropic", config.ProviderConfig{Token: "sk-ant-test", AuthMode: config.AuthModeSubscription})
+			},
+			want: map[string]string{},
+		},
+		{
+			name:     "glm with model mappings",
+			provider: "glm",
+			want: map[string]string{
+				config.EnvAuthToken:   "glm-token",
+				config.EnvBaseURL:     "https://api.z.ai/api/anthropic",
+				config.EnvHaikuModel:  "glm-4.5-air",
+				config.EnvSonnetModel: "glm-4.6",
+			},
+		},
+		{
+			name:     "custom without model mappings",
+			provider: "custom",
+			want: map[string]string{
+				config.EnvAuthToken: "custom-token",
+				config.EnvBaseURL:   "https://proxy.example.com",
+			},
+		},
+		{
+			name:     "custom with extra env vars",
+			provider: "custom",
+			setup: func(c *config.Config) {
+				c.SetProviderConfig("custom", config.ProviderConfig{
+					Token:   "custom-token",
+					BaseURL: "https://proxy.example.com",
+					EnvVars: map[string]string{
+						"API_TIMEOUT_MS":  "3000000",
+						config.EnvBaseURL: "https://ignored.example.com",
+					},
+				})
+			},
+			want: map[string]string{
+				"API_TIMEOUT_MS":    "3000000",
+				config.EnvAuthToken: "custom-token",
+				config.EnvBaseURL:   "https://proxy.example.com",
+			},
+		},
+		{
+			name:     "unknown provider",
+			provider: "missing",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := *cfg
+			c.Providers = make(map[string]config.ProviderConfig)
+			for name, p := range cfg.Providers {
+				c.Providers[name] = p
+			}
+			if tt.setup != nil {
+				tt.setup(&c)
+			}
+
+			env, err := c.GenerateSettingsPreview(tt.provider)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("Expected error for unknown provider")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(env) != len(tt.want) {
+				t.Errorf("Expected %d env vars, got %d: %v", len(tt.want), len(env), env)
+			}
+			for key, value := range tt.want {
+				if env[key] != value {
+					t.Errorf("Expected %s=%s, got %s", key, value, env[key])
+				}
+			}
+		})
+	}
+}
+
+func TestValidateProviderRejectsManagedEnvVars(t *testing.T) {
+	cfg := config.NewConfig()
+	for _, key := range []string{config.EnvAuthToken, config.EnvBaseURL, config.EnvOpusModel} {
+		cfg.SetProviderConfig("custom", config.ProviderConfig{
+			Token:   "custom-token",
+			BaseURL: "https://proxy.example.com",
+			EnvVars: map[string]string{key: "x"},
+		})
+		if err := cfg.ValidateProvider("custom"); err == nil || !strings.Contains(err.Error(), key) {
+			t.Errorf("Expected %s to be rejected as an extra env var, got %v", key, err)
+		}
+	}
+
+	cfg.SetProviderConfig("custom", config.ProviderConfig{
+		Token:   "custom-token",
+		BaseURL: "https://proxy.example.com",
+		EnvVars: map[string]string{"API_TIMEOUT_MS": "3000000"},
+	})
+	if err := cfg.ValidateProvider("custom"); err != nil {
+		t.Errorf("Expected other env vars to be accepted, got %v", err)
+	}
+}
+
+func TestSaveConfigKeepsBackup(t *testing.T) {
+	isolateHome(t)
+
+	cfg := config.NewConfig()
+	if err := config.SaveConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(config.GetConfigBackupPath()); !os.IsNotExist(err) {
+		t.Error("First save should not create a backup")
+	}
+
+	first, err := os.ReadFile(config.GetConfigPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg.SetProviderConfig(testProvider, config.ProviderConfig{BaseURL: "https://test.example.com"})
+	if err := config.SaveConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	backup, err := os.ReadFile(config.GetConfigBackupPath())
+	if err != nil {
+		t.Fatalf("Expected a backup of the previous config: %v", err)
+	}
+	if string(backup) != string(first) {
+		t.Errorf("Backup should hold the previous config, got:\n%s", backup)
+	}
+
+	// Saving unchanged content must not overwrite the backup with itself
+	if err := config.SaveConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+	backup, err = os.ReadFile(config.GetConfigBackupPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(backup) != string(first) {
+		t.Error("Unchanged save replaced the backup")
+	}
+}
+
+func TestRenameProvider(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.SetProviderConfig("glm", config.ProviderConfig{Token: "glm-token", BaseURL: "https://api.z.ai/api/anthropic"})
+	if err := cfg.SetActiveProvider("glm"); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.SaveProfile("work"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cfg.RenameProvider("glm", "glm-prod"); err != nil {
+		t.Fatalf("RenameProvider failed: %v", err)
+	}
+
+	if _, exists := cfg.Providers["glm"]; exists {
+		t.Error("Old provider name should be gone")
+	}
+	if cfg.Providers["glm-prod"].Token != "glm-token" {
+		t.Error("Provider config should move to the new name")
+	}
+	if cfg.Provider != "glm-prod" {
+		t.Errorf("Expected active provider to follow the rename, got %s", cfg.Provider)
+	}
+	if cfg.Profiles["work"].Provider != "glm-prod" {
+		t.Errorf("Expected profile to follow the rename, got %s", cfg.Profiles["work"].Provider)
+	}
+
+	for _, tt := range []struct{ from, to string }{
+		{"missing", "other"},
+		{"anthropic", "claude"},
+		{"glm-prod", "anthropic"},
+		{"glm-prod", "Bad Name"},
+	} {
+		if err := cfg.RenameProvider(tt.from, tt.to); err == nil {
+			t.Errorf("Expected renaming %s to %s to fail", tt.from, tt.to)
+		}
+	}
+}
+
+func TestResolveAlias(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.SetProviderConfig("glm-prod", config.ProviderConfig{BaseURL: "https://api.z.ai/api/anthropic", Aliases: []string{"glm"}})
+	cfg.SetProviderConfig("glm-staging", config.ProviderConfig{BaseURL: "https://staging.example.com", Aliases: []string{"stage"}})
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"glm", "glm-prod"},
+		{"glm-staging", "glm-staging"},
+		{"stage", "glm-staging"},
+		{"unknown", "unknown"},
+	}
+	for _, tt := range tests {
+		got, err := cfg.ResolveAlias(tt.name)
+		if err != nil {
+			t.Fatalf("ResolveAlias(%q) failed: %v", tt.name, err)
+		}
+		if got != tt.want {
+			t.Errorf("ResolveAlias(%q) = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected distinct aliases to validate: %v", err)
+	}
+
+	// Two providers claiming the same alias is ambiguous
+	staging := cfg.Providers["glm-staging"]
+	staging.Aliases = append(staging.Aliases, "glm")
+	cfg.SetProviderConfig("glm-staging", staging)
+	if _, err := cfg.ResolveAlias("glm"); err == nil {
+		t.Error("Expected an ambiguous alias to fail")
+	}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected validation to reject an alias claimed twice")
+	}
+
+	// An alias must not shadow a provider name
+	staging.Aliases = []string{"glm-prod"}
+	cfg.SetProviderConfig("glm-staging", staging)
+	if err := cfg.ValidateProvider("glm-staging"); err == nil {
+		t.Error("Expected validation to reject an alias that is a provider name")
+	}
+}
+
+func TestLockConfigSerializesUpdates(t *testing.T) {
+	isolateHome(t)
+
+	cfg := config.NewConfig()
+	cfg.SetProviderConfig(testProvider, config.ProviderConfig{BaseURL: "https://test.example.com"})
+	if err := config.SaveConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	const rounds = 20
+	var wg sync.WaitGroup
+	errs := make(chan error, len(config.ModelCategories)*rounds)
+	for _, category := range config.ModelCategories {
+		wg.Add(1)
+		go func(category string) {
+			defer wg.Done()
+			for i := 0; i < rounds; i++ {
+				errs <- setModelLocked(category, fmt.Sprintf("%s-%d", category, i))
+			}
+		}(category)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, category := range config.ModelCategories {
+		want := fmt.Sprintf("%s-%d", category, rounds-1)
+		if got := cfg.Providers[testProvider].ModelMap[category]; got != want {
+			t.Errorf("Expected %s to be %s, got %q; an update was lost", category, want, got)
+		}
+	}
+}
+
+// setModelLocked maps a category of the test provider in a locked
+// read-modify-write of the config
+func setModelLocked(category, model string) error {
+	unlock, err := config.LockConfig(config.LockTimeout)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return err
+	}
+	provider := cfg.Providers[testProvider]
+	if provider.ModelMap == nil {
+		provider.ModelMap = make(map[string]string)
+	}
+	provider.ModelMap[category] = model
+	cfg.SetProviderConfig(testProvider, provider)
+	return config.SaveConfig(cfg)
+}
+
+func TestLockConfigTimesOut(t *testing.T) {
+	isolateHome(t)
+
+	unlock, err := config.LockConfig(config.LockTimeout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unlock()
+
+	if _, err := config.LockConfig(100 * time.Millisecond); err == nil || !strings.Contains(err.Error(), "another cflip process holds the lock") {
+		t.Errorf("Expected a held lock error, got %v", err)
+	}
+}
+
+func TestResolveConfigPath(t *testing.T) {
+	home := isolateHome(t)
+	t.Cleanup(func() { config.SetConfigPath("") })
+
+	// An existing ~/.cflip config is used on every platform
+	if err := os.MkdirAll(filepath.Join(home, ".cflip"), 0750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(home, ".cflip", "config.toml"), nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		flag       string
+		env        string
+		wantPath   string
+		wantSource string
+	}{
+		{"default", "", "", filepath.Join(home, ".cflip", "config.toml"), "default"},
+		{"env", "", "/env/config.toml", "/env/config.toml", config.ConfigEnvVar},
+		{"flag wins over env", "/flag/config.toml", "/env/config.toml", "/flag/config.toml", "--config"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(config.ConfigEnvVar, tt.env)
+			config.SetConfigPath(tt.flag)
+
+			path, source := config.ResolveConfigPath()
+			if path != tt.wantPath || source != tt.wantSource {
+				t.Errorf("ResolveConfigPath() = (%q, %q), want (%q, %q)", path, source, tt.wantPath, tt.wantSource)
+			}
+		})
+	}
+}
+
+func TestConfigFileExplicitPath(t *testing.T) {
+	home := isolateHome(t)
+
+	configPath := filepath.Join(t.TempDir(), "nested", "config.toml")
+	cfg := config.NewConfig()
+	cfg.SetProviderConfig(testProvider, config.ProviderConfig{BaseURL: "https://test.example.com"})
+	if err := config.SaveConfigFile(configPath, cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := config.LoadConfigFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Providers[testProvider].BaseURL != "https://test.example.com" {
+		t.Errorf("Expected the saved provider to load back, got %+v", loaded.Providers)
+	}
+	if _, err := os.Stat(filepath.Join(home, ".cflip")); !os.IsNotExist(err) {
+		t.Error("Saving to an explicit path must not touch the home directory")
+	}
+}
+
+func TestResolveDirs(t *testing.T) {
+	home := filepath.FromSlash("/home/me")
+	legacy := filepath.Join(home, ".cflip")
+	xdgConfig := filepath.FromSlash("/xdg/config")
+	xdgState := filepath.FromSlash("/xdg/state")
+
+	tests := []struct {
+		name          string
+		xdgConfigHome string
+		xdgStateHome  string
+		goos          string
+		legacyExists  bool
+		wantConfigDir string
+		wantStateDir  string
+	}{
+		{"HOME only", "", "", "linux", false,
+			filepath.Join(home, ".config", "cflip"), filepath.Join(home, ".local", "state", "cflip")},
+		{"XDG set", xdgConfig, xdgState, "linux", false,
+			filepath.Join(xdgConfig, "cflip"), filepath.Join(xdgState, "cflip")},
+		{"both present keeps legacy", xdgConfig, xdgState, "linux", true, legacy, legacy},
+		{"HOME only with legacy", "", "", "linux", true, legacy, legacy},
+		{"macOS", xdgConfig, xdgState, "darwin", false, legacy, legacy},
+		{"Windows", "", "", "windows", false, legacy, legacy},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exists := func(path string) bool {
+				return tt.legacyExists && path == filepath.Join(legacy, "config.toml")
+			}
+			configDir, stateDir := config.ResolveDirs(home, tt.xdgConfigHome, tt.xdgStateHome, tt.goos, exists)
+			if configDir != tt.wantConfigDir || stateDir != tt.wantStateDir {
+				t.Errorf("ResolveDirs() = (%q, %q), want (%q, %q)", configDir, stateDir, tt.wantConfigDir, tt.wantStateDir)
+			}
+		})
+	}
+}
+
+func TestMigrateLegacyDir(t *testing.T) {
+	if runtime.GOOS == "darwin" || runtime.GOOS == "windows" {
+		t.Skip("XDG directories are not used on " + runtime.GOOS)
+	}
+
+	home := isolateHome(t)
+	t.Setenv("XDG_STATE_HOME", filepath.Join(home, "state"))
+
+	legacyDir := filepath.Join(home, ".cflip")
+	if err := os.MkdirAll(legacyDir, 0750); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{"config.toml": `provider = "anthropic"`, "config.toml.bak": "old"} {
+		if err := os.WriteFile(filepath.Join(legacyDir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := config.GetConfigPath(); got != filepath.Join(legacyDir, "config.toml") {
+		t.Fatalf("Expected the legacy config to be used before migrating, got %s", got)
+	}
+
+	configPath, stateDir, err := config.MigrateLegacyDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(home, ".config", "cflip", "config.toml"); configPath != want {
+		t.Errorf("Expected config at %s, got %s", want, configPath)
+	}
+	if got := config.GetConfigPath(); got != configPath {
+		t.Errorf("Expected the migrated config to be resolved, got %s", got)
+	}
+	if backup, err := os.ReadFile(filepath.Join(stateDir, "config.toml.bak")); err != nil || string(backup) != "old" {
+		t.Errorf("Expected the backup in %s: %v", stateDir, err)
+	}
+	if config.GetConfigBackupPath() != filepath.Join(stateDir, "config.toml.bak") {
+		t.Errorf("Expected backups in the state directory, got %s", config.GetConfigBackupPath())
+	}
+	if pointer, err := os.ReadFile(filepath.Join(legacyDir, config.MovedFileName)); err != nil || !strings.Contains(string(pointer), configPath) {
+		t.Errorf("Expected a pointer to %s, got %q (%v)", configPath, pointer, err)
+	}
+
+	if _, _, err := config.MigrateLegacyDir(); err == nil {
+		t.Error("A second migration should fail")
+	}
+}
+
+func TestRedact(t *testing.T) {
+	tests := map[string]string{
+		"sk-ant-abcdef123456": "sk-a*************56",
+		"short-token":         "***********",
+		"":                    "",
+	}
+	for token, want := range tests {
+		if got := config.Redact(token); got != want {
+			t.Errorf("Redact(%q) = %q, want %q", token, got, want)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/vanducng/cflip/internal/config"
+)
+
+// autoSwitchCmd rotates through providers on a schedule
+var autoSwitchCmd = &cobra.Command{
+	Use:   "auto-switch",
+	Short: "Rotate to the next provider once an interval has passed",
+	Long: `Switch to the next provider in --providers once --interval has passed since
+the last switch. When the interval has not passed yet nothing happens, so the
+command is safe to run from cron or launchd:
+
+  0 * * * * cflip auto-switch --providers glm,anthropic --interval 24h -q
+
+If cflip has no record of a previous switch, the first run switches right
+away and starts the clock. Every provider in the rotation must already be
+configured; auto-switch never prompts. Use --force to switch regardless of
+the interval.`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         runAutoSwitch,
+}
+
+func init() {
+	autoSwitchCmd.Flags().StringSlice("providers", nil, "Providers to rotate through, in order (required)")
+	autoSwitchCmd.Flags().Duration("interval", 24*time.Hour, "Minimum time between switches")
+	autoSwitchCmd.Flags().Bool("force", false, "Switch even if the interval has not passed")
+	autoSwitchCmd.Flags().Bool("no-hooks", false, "Do not run the pre and post switch hooks")
+	autoSwitchCmd.Flags().Bool("no-snapshot", false, "Do not snapshot the current settings before writing")
+	autoSwitchCmd.Flags().String("settings-path", "", "Claude settings file to write (default ~/.claude/settings.json)")
+	_ = autoSwitchCmd.MarkFlagRequired("providers")
+	_ = autoSwitchCmd.RegisterFlagCompletionFunc("providers", completeProviderNames)
+}
+
+// NewAutoSwitchCmd exports the auto-switch command
+func NewAutoSwitchCmd() *cobra.Command {
+	return autoSwitchCmd
+}
+
+func runAutoSwitch(cmd *cobra.Command, args []string) error {
+	verbose, _ := cmd.Flags().GetBool("verbose")
+	quiet, _ := cmd.Flags().GetBool("quiet")
+	providers, _ := cmd.Flags().GetStringSlice("providers")
+	interval, _ := cmd.Flags().GetDuration("interval")
+	force, _ := cmd.Flags().GetBool("force")
+	noHooks, _ := cmd.Flags().GetBool("no-hooks")
+	noSnapshot, _ := cmd.Flags().GetBool("no-snapshot")
+
+	if len(providers) == 0 {
+		return fmt.Errorf("--providers needs at least one provider")
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	// Reject unknown names up front rather than when the rotation reaches them
+	for _, name := range providers {
+		name = strings.TrimSpace(name)
+		if _, exists := cfg.Providers[name]; !exists {
+			return fmt.Errorf("provider '%s' is not configured; set it up with cflip switch %s first", name, name)
+		}
+	}
+
+	// A zero SwitchedAt means no switch was recorded, so the interval has passed
+	if !force && !cfg.SwitchedAt.IsZero() {
+		if elapsed := time.Since(cfg.SwitchedAt); elapsed < interval {
+			if verbose && !quiet {
+				fmt.Printf("Next switch in %s\n", (interval - elapsed).Round(time.Minute))
+			}
+			return nil
+		}
+	}
+
+	next := nextInRotation(providers, cfg.Provider)
+	if next == cfg.Provider {
+		return nil
+	}
+
+	if provider, _ := cfg.ResolveProvider(next); cfg.NeedsAPIKey(next) && provider.Token == "" {
+		return fmt.Errorf("provider '%s' has no API key; set one with cflip config set-api-key %s", next, next)
+	}
+
+	oldProvider := cfg.Provider
+	if !noHooks {
+		if err := runSwitchHook(cmd.Context(), cfg.Hooks.PreSwitch, oldProvider, next); err != nil {
+			return fmt.Errorf("pre-switch %w; switch aborted", err)
+		}
+	}
+
+	if err := cfg.SetActiveProvider(next); err != nil {
+		return err
+	}
+	if err := config.SaveConfig(cfg); err != nil {
+		return fmt.Errorf("failed to save configuration: %w", err)
+	}
+	if err := generateSwitchSettings(cfg, resolveSettingsPath(cmd), oldProvider, noSnapshot, verbose, quiet); err != nil {
+		return fmt.Errorf("failed to generate Claude settings: %w", err)
+	}
+
+	if !quiet {
+		fmt.Printf("%s Switched from %s to %s\n", checkMark(), oldProvider, next)
+	}
+
+	if !noHooks {
+		if err := runSwitchHook(cmd.Context(), cfg.Hooks.PostSwitch, oldProvider, next); err != nil {
+			fmt.Printf("Warning: Post-switch %v\n", err)
+		}
+	}
+	return nil
+}
+
+// nextInRotation returns the provider after current in the ring, or the
+// first one when current is not part of it
+func nextInRotation(ring []string, current string) string {
+	for i, name := range ring {
+		if strings.TrimSpace(name) == current {
+			return strings.TrimSpace(ring[(i+1)%len(ring)])
+		}
+	}
+	return strings.TrimSpace(ring[0])
+}
@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vanducng/cflip/internal/config"
+)
+
+// seedSwitchedAt makes the given provider active as of switchedAt
+func seedSwitchedAt(t *testing.T, provider string, switchedAt time.Time) {
+	t.Helper()
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Provider = provider
+	cfg.SwitchedAt = switchedAt
+	if err := config.SaveConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAutoSwitch(t *testing.T) {
+	tests := []struct {
+		name       string
+		switchedAt time.Time
+		force      bool
+		want       string
+	}{
+		{"interval not elapsed", time.Now().Add(-time.Hour), false, anthropicProvider},
+		{"interval elapsed", time.Now().Add(-25 * time.Hour), false, glmProvider},
+		{"forced", time.Now().Add(-time.Hour), true, glmProvider},
+		{"never switched", time.Time{}, false, glmProvider},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupTestHome(t)
+			resetFlags(t, autoSwitchCmd, "providers", "force")
+			seedSwitchedAt(t, anthropicProvider, tt.switchedAt)
+
+			_ = autoSwitchCmd.Flags().Set("providers", "anthropic,glm")
+			_ = autoSwitchCmd.Flags().Set("force", boolString(tt.force))
+
+			if err := runAutoSwitch(autoSwitchCmd, nil); err != nil {
+				t.Fatalf("auto-switch failed: %v", err)
+			}
+
+			cfg, err := config.LoadConfig()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cfg.Provider != tt.want {
+				t.Errorf("Expected %s to be active, got %s", tt.want, cfg.Provider)
+			}
+		})
+	}
+}
+
+func TestAutoSwitchRejectsUnconfiguredProvider(t *testing.T) {
+	setupTestHome(t)
+	resetFlags(t, autoSwitchCmd, "providers", "force")
+	seedSwitchedAt(t, anthropicProvider, time.Now().Add(-time.Hour))
+
+	// The unknown entry is not next in the ring, and the interval has not passed
+	_ = autoSwitchCmd.Flags().Set("providers", "anthropic,glm,missing")
+
+	err := runAutoSwitch(autoSwitchCmd, nil)
+	if err == nil || !strings.Contains(err.Error(), "'missing' is not configured") {
+		t.Fatalf("Expected an error naming the unconfigured provider, got %v", err)
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Provider != anthropicProvider {
+		t.Errorf("Expected anthropic to stay active, got %s", cfg.Provider)
+	}
+}
+
+func TestNextInRotation(t *testing.T) {
+	ring := []string{"anthropic", "glm", "litellm"}
+
+	tests := []struct {
+		current string
+		want    string
+	}{
+		{"anthropic", "glm"},
+		{"glm", "litellm"},
+		{"litellm", "anthropic"},
+		{"other", "anthropic"},
+	}
+	for _, tt := range tests {
+		if got := nextInRotation(ring, tt.current); got != tt.want {
+			t.Errorf("nextInRotation(%s) = %s, want %s", tt.current, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/vanducng/cflip/internal/config"
+)
+
+func TestCompletionScripts(t *testing.T) {
+	registerCommands()
+
+	for _, shell := range []string{"bash", "zsh", "fish", "powershell"} {
+		t.Run(shell, func(t *testing.T) {
+			var out bytes.Buffer
+			completionCmd.SetOut(&out)
+			t.Cleanup(func() { completionCmd.SetOut(nil) })
+
+			if err := runCompletion(completionCmd, []string{shell}); err != nil {
+				t.Fatalf("completion %s failed: %v", shell, err)
+			}
+			if out.Len() == 0 {
+				t.Errorf("Expected a %s completion script", shell)
+			}
+		})
+	}
+
+	if err := runCompletion(completionCmd, []string{"tcsh"}); err == nil {
+		t.Error("Expected an error for an unsupported shell")
+	}
+}
+
+func TestCompleteProviderNames(t *testing.T) {
+	setupTestHome(t)
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.SetProviderConfig("my-proxy", config.ProviderConfig{BaseURL: "http://localhost:4000"})
+	if err := config.SaveConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	names, directive := completeProviderNames(switchCmd, nil, "")
+	if directive != cobra.ShellCompDirectiveNoFileComp {
+		t.Errorf("Expected no file completion, got %v", directive)
+	}
+
+	expected := []string{anthropicProvider, glmProvider, "my-proxy"}
+	if len(names) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, names)
+	}
+	for i, name := range expected {
+		if names[i] != name {
+			t.Errorf("Expected %v, got %v", expected, names)
+		}
+	}
+
+	// switch takes a single provider
+	if names, _ := completeProviderNames(switchCmd, []string{glmProvider}, ""); len(names) != 0 {
+		t.Errorf("Expected no completions after the first argument, got %v", names)
+	}
+}
@@ -0,0 +1,300 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/vanducng/cflip/internal/config"
+	"golang.org/x/term"
+)
+
+// configSetAPIKeyCmd stores the API key of a provider
+var configSetAPIKeyCmd = &cobra.Command{
+	Use:   "set-api-key <provider>",
+	Short: "Set the API key of a provider",
+	Long: `Store the API key of a provider in the cflip config file. The key is
+prompted for without echo when run in a terminal; use --stdin or --from-file
+in scripts:
+
+  echo "$GLM_KEY" | cflip config set-api-key glm --stdin
+  cflip config set-api-key glm --from-file /run/secrets/glm
+
+With --validate, or auto = true under [validation] in the config, the key
+is tested against the provider first, the same way cflip test does, and a
+rejected key is not saved. --skip-validate saves without testing, e.g.
+without network access.
+
+Claude settings are regenerated when the provider is active.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeProviderNames,
+	RunE:              runConfigSetAPIKey,
+}
+
+// configRemoveAPIKeyCmd deletes the API key of a provider
+var configRemoveAPIKeyCmd = &cobra.Command{
+	Use:   "remove-api-key <provider>",
+	Short: "Remove the API key of a provider",
+	Long: `Remove the stored API key of a provider from the cflip config file.
+Claude settings are regenerated without the key when the provider is active.
+With --purge-snapshots the key is also removed from every settings snapshot.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeProviderNames,
+	RunE:              runConfigRemoveAPIKey,
+}
+
+func init() {
+	configSetAPIKeyCmd.Flags().Bool("stdin", false, "Read the API key from standard input")
+	configSetAPIKeyCmd.Flags().String("from-file", "", "Read the API key from a file")
+	configSetAPIKeyCmd.Flags().String("settings-path", "", "Claude settings file to write (default ~/.claude/settings.json)")
+	configSetAPIKeyCmd.Flags().Bool("validate", false, "Test the key against the provider before saving it")
+	configSetAPIKeyCmd.Flags().Bool("skip-validate", false, "Save without testing the key, even with validation enabled in the config")
+
+	configRemoveAPIKeyCmd.Flags().BoolP("yes", "y", false, "Do not ask for confirmation")
+	configRemoveAPIKeyCmd.Flags().Bool("purge-snapshots", false, "Also remove the key from settings snapshots")
+	configRemoveAPIKeyCmd.Flags().String("settings-path", "", "Claude settings file to write (default ~/.claude/settings.json)")
+
+	configCmd.AddCommand(configSetAPIKeyCmd)
+	configCmd.AddCommand(configRemoveAPIKeyCmd)
+}
+
+func runConfigSetAPIKey(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	verbose, _ := cmd.Flags().GetBool("verbose")
+	quiet, _ := cmd.Flags().GetBool("quiet")
+	fromStdin, _ := cmd.Flags().GetBool("stdin")
+	fromFile, _ := cmd.Flags().GetString("from-file")
+	validate, _ := cmd.Flags().GetBool("validate")
+	skipValidate, _ := cmd.Flags().GetBool("skip-validate")
+
+	if fromStdin && fromFile != "" {
+		return fmt.Errorf("--stdin and --from-file cannot be combined")
+	}
+	if validate && skipValidate {
+		return fmt.Errorf("--validate and --skip-validate cannot be combined")
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	provider, exists := cfg.Providers[name]
+	if !exists {
+		return fmt.Errorf("provider '%s' not found (add it with cflip config add-provider)", name)
+	}
+
+	key, err := readAPIKey(name, fromStdin, fromFile)
+	if err != nil {
+		return err
+	}
+	if err := validateAPIKeyFormat(key); err != nil {
+		return err
+	}
+
+	// A validation of the old key says nothing about the new one
+	if provider.Token != key {
+		provider.LastValidated = time.Time{}
+	}
+	provider.Token = key
+	validated := false
+	if (validate || cfg.Validation.Auto) && !skipValidate {
+		if validated, err = validateNewAPIKey(cmd, name, provider, cfg.IsExternal(name), quiet); err != nil {
+			return err
+		}
+	}
+	cfg.SetProviderConfig(name, provider)
+	if validated {
+		if err := cfg.MarkValidated(name, time.Now()); err != nil {
+			return err
+		}
+	}
+
+	if err := config.SaveConfig(cfg); err != nil {
+		return fmt.Errorf("failed to save configuration: %w", err)
+	}
+
+	if cfg.Provider == name {
+		if err := generateClaudeSettings(cfg, resolveSettingsPath(cmd), false, verbose, quiet); err != nil {
+			return fmt.Errorf("failed to generate Claude settings: %w", err)
+		}
+	}
+
+	if !quiet {
+		fmt.Printf("%s Set API key for %s\n", checkMark(), name)
+	}
+	return nil
+}
+
+// validateNewAPIKey tests a key before it is saved and reports whether the
+// provider accepted it. A rejected key is an error; a provider that cannot
+// be reached is only reported, so the key is saved unvalidated.
+func validateNewAPIKey(cmd *cobra.Command, name string, provider config.ProviderConfig, external, quiet bool) (bool, error) {
+	client := &http.Client{Timeout: defaultConnectionTimeout}
+	result := testProviderKey(cmd.Context(), client, name, provider, external)
+
+	switch {
+	case result.OK:
+		if !quiet {
+			fmt.Printf("%s %s accepted the key (%s)\n", checkMark(), name, statusText(result.Status))
+		}
+		return true, nil
+	case result.Status == http.StatusUnauthorized || result.Status == http.StatusForbidden:
+		return false, fmt.Errorf("%s: %s (%s); the key was not saved. Check it in the provider's console, or save it anyway with --skip-validate",
+			name, result.Reason, statusText(result.Status))
+	case !quiet:
+		fmt.Printf("Warning: could not validate the key for %s: %s; saving it anyway\n", name, result.Reason)
+	}
+	return false, nil
+}
+
+func runConfigRemoveAPIKey(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	verbose, _ := cmd.Flags().GetBool("verbose")
+	quiet, _ := cmd.Flags().GetBool("quiet")
+	yes, _ := cmd.Flags().GetBool("yes")
+	purge, _ := cmd.Flags().GetBool("purge-snapshots")
+	settingsPath := resolveSettingsPath(cmd)
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	provider, exists := cfg.Providers[name]
+	if !exists {
+		return fmt.Errorf("provider '%s' not found", name)
+	}
+	if provider.Token == "" {
+		return fmt.Errorf("provider '%s' has no stored API key", name)
+	}
+
+	if !yes {
+		fmt.Printf("Remove the API key for %s? (y/N): ", name)
+		reader := bufio.NewReader(os.Stdin)
+		input, _ := reader.ReadString('\n')
+		input = strings.TrimSpace(strings.ToLower(input))
+		if input != "y" && input != yesResponse {
+			fmt.Println("Aborted")
+			return nil
+		}
+	}
+
+	if err := cfg.ClearAPIKey(name); err != nil {
+		return err
+	}
+
+	if err := config.SaveConfig(cfg); err != nil {
+		return fmt.Errorf("failed to save configuration: %w", err)
+	}
+
+	if cfg.Provider == name {
+		if err := generateClaudeSettings(cfg, settingsPath, false, verbose, quiet); err != nil {
+			return fmt.Errorf("failed to generate Claude settings: %w", err)
+		}
+	}
+
+	// Purge after regenerating so the snapshot taken by the switch is scrubbed too
+	var purged []string
+	if purge {
+		purged, err = purgeKeyFromSnapshots(snapshotsDirFor(settingsPath), provider.Token)
+		if err != nil {
+			return err
+		}
+	}
+
+	if !quiet {
+		fmt.Printf("%s Removed API key for %s\n", checkMark(), name)
+		if purge {
+			fmt.Printf("%s Removed the key from %d snapshot(s)\n", checkMark(), len(purged))
+		}
+		if verbose {
+			for _, snapshot := range purged {
+				fmt.Printf("  %s\n", snapshot)
+			}
+		}
+	}
+	return nil
+}
+
+// purgeKeyFromSnapshots deletes every env entry holding key from the
+// snapshots in dir and returns the names of the snapshots it changed
+func purgeKeyFromSnapshots(dir, key string) ([]string, error) {
+	snapshots, err := ListSnapshots(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+
+	var changed []string
+	for _, name := range snapshots {
+		path := filepath.Join(dir, name)
+		settings, err := LoadSettings(path)
+		if err != nil {
+			continue
+		}
+
+		modified := false
+		for envKey, value := range settings.Env {
+			if s, ok := value.(string); ok && s == key {
+				delete(settings.Env, envKey)
+				modified = true
+			}
+		}
+		if !modified {
+			continue
+		}
+
+		if err := SaveSettings(path, settings); err != nil {
+			return changed, fmt.Errorf("failed to update snapshot %s: %w", name, err)
+		}
+		changed = append(changed, name)
+	}
+
+	return changed, nil
+}
+
+// readAPIKey reads a key from stdin, a file, or an interactive prompt
+func readAPIKey(providerName string, fromStdin bool, fromFile string) (string, error) {
+	switch {
+	case fromStdin:
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return "", fmt.Errorf("failed to read API key from stdin: %w", err)
+		}
+		return strings.TrimSpace(string(data)), nil
+	case fromFile != "":
+		data, err := os.ReadFile(fromFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read API key file: %w", err)
+		}
+		return strings.TrimSpace(string(data)), nil
+	case !term.IsTerminal(int(os.Stdin.Fd())):
+		return "", fmt.Errorf("no terminal to prompt for the API key; use --stdin or --from-file")
+	}
+
+	fmt.Printf("Enter %s API key: ", providerName)
+	bytePassword, err := term.ReadPassword(int(os.Stdin.Fd()))
+	if err != nil {
+		return "", fmt.Errorf("failed to read API key: %w", err)
+	}
+	fmt.Println() // New line after password input
+
+	return strings.TrimSpace(string(bytePassword)), nil
+}
+
+// validateAPIKeyFormat rejects keys that cannot be valid for any provider
+func validateAPIKeyFormat(key string) error {
+	if key == "" {
+		return fmt.Errorf("API key cannot be empty")
+	}
+	if strings.ContainsAny(key, " \t\r\n") {
+		return fmt.Errorf("API key must not contain whitespace")
+	}
+	return nil
+}
@@ -0,0 +1,268 @@
+package cli
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/vanducng/cflip/internal/config"
+)
+
+// withStdin replaces stdin with a pipe that yields input
+func withStdin(t *testing.T, input string) {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.WriteString(input); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+
+	orig := os.Stdin
+	os.Stdin = r
+	t.Cleanup(func() {
+		os.Stdin = orig
+		r.Close()
+	})
+}
+
+func TestConfigSetAPIKeyFromStdin(t *testing.T) {
+	setupTestHome(t)
+	resetFlags(t, configSetAPIKeyCmd, "stdin")
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.SetActiveProvider(glmProvider); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.SaveConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	withStdin(t, "glm-new-token\n")
+	_ = configSetAPIKeyCmd.Flags().Set("stdin", "true")
+	if err := runConfigSetAPIKey(configSetAPIKeyCmd, []string{glmProvider}); err != nil {
+		t.Fatalf("set-api-key failed: %v", err)
+	}
+
+	cfg, err = config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.Providers[glmProvider].Token; got != "glm-new-token" {
+		t.Errorf("Expected trimmed key from stdin, got %q", got)
+	}
+
+	// The provider is active, so settings must be regenerated
+	settings, err := LoadSettings(defaultSettingsPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := settings.Env[config.EnvAuthToken]; got != "glm-new-token" {
+		t.Errorf("Expected regenerated settings with the new key, got %v", got)
+	}
+}
+
+func TestConfigSetAPIKeyFromFile(t *testing.T) {
+	setupTestHome(t)
+	resetFlags(t, configSetAPIKeyCmd, "from-file")
+
+	keyFile := filepath.Join(t.TempDir(), "key")
+	if err := os.WriteFile(keyFile, []byte("file-token\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	_ = configSetAPIKeyCmd.Flags().Set("from-file", keyFile)
+	if err := runConfigSetAPIKey(configSetAPIKeyCmd, []string{glmProvider}); err != nil {
+		t.Fatalf("set-api-key failed: %v", err)
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.Providers[glmProvider].Token; got != "file-token" {
+		t.Errorf("Expected key from file, got %q", got)
+	}
+
+	// An inactive provider leaves Claude settings alone
+	if _, err := os.Stat(defaultSettingsPath()); !os.IsNotExist(err) {
+		t.Error("Settings should not be written for an inactive provider")
+	}
+}
+
+func TestConfigSetAPIKeyValidate(t *testing.T) {
+	setupTestHome(t)
+	resetFlags(t, configSetAPIKeyCmd, "from-file", "validate", "skip-validate")
+
+	// The provider only accepts good-token
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer good-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider := cfg.Providers[glmProvider]
+	provider.BaseURL = server.URL
+	cfg.SetProviderConfig(glmProvider, provider)
+	if err := config.SaveConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	setKey := func(key string) error {
+		keyFile := filepath.Join(t.TempDir(), "key")
+		if err := os.WriteFile(keyFile, []byte(key), 0600); err != nil {
+			t.Fatal(err)
+		}
+		_ = configSetAPIKeyCmd.Flags().Set("from-file", keyFile)
+		return runConfigSetAPIKey(configSetAPIKeyCmd, []string{glmProvider})
+	}
+	savedProvider := func() config.ProviderConfig {
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cfg.Providers[glmProvider]
+	}
+
+	_ = configSetAPIKeyCmd.Flags().Set("validate", "true")
+	if err := setKey("bad-token"); err == nil || !strings.Contains(err.Error(), "not saved") {
+		t.Fatalf("Expected the rejected key not to be saved, got %v", err)
+	}
+	if got := savedProvider(); got.Token != "glm-test-token" || !got.LastValidated.IsZero() {
+		t.Errorf("Expected the old key to be kept, got %+v", got)
+	}
+
+	captureStdout(t, func() error { return setKey("good-token") })
+	if got := savedProvider(); got.Token != "good-token" || got.LastValidated.IsZero() {
+		t.Errorf("Expected the accepted key to be saved and stamped, got %+v", got)
+	}
+
+	// Validation enabled in the config, overridden for an air-gapped machine
+	_ = configSetAPIKeyCmd.Flags().Set("validate", "false")
+	cfg, _ = config.LoadConfig()
+	cfg.Validation.Auto = true
+	if err := config.SaveConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if err := setKey("offline-token"); err == nil {
+		t.Error("Expected auto validation to reject the key")
+	}
+	_ = configSetAPIKeyCmd.Flags().Set("skip-validate", "true")
+	captureStdout(t, func() error { return setKey("offline-token") })
+	if got := savedProvider(); got.Token != "offline-token" || !got.LastValidated.IsZero() {
+		t.Errorf("Expected --skip-validate to save the key unvalidated, got %+v", got)
+	}
+}
+
+func TestConfigSetAPIKeyErrors(t *testing.T) {
+	setupTestHome(t)
+	resetFlags(t, configSetAPIKeyCmd, "stdin")
+
+	// No terminal and no flag
+	withEmptyStdin(t)
+	if err := runConfigSetAPIKey(configSetAPIKeyCmd, []string{glmProvider}); err == nil {
+		t.Error("Expected an error without a terminal or input flag")
+	}
+
+	// Empty and malformed keys are rejected
+	for _, input := range []string{"\n", "two words\n"} {
+		withStdin(t, input)
+		_ = configSetAPIKeyCmd.Flags().Set("stdin", "true")
+		if err := runConfigSetAPIKey(configSetAPIKeyCmd, []string{glmProvider}); err == nil {
+			t.Errorf("Expected an error for key %q", input)
+		}
+	}
+
+	if err := runConfigSetAPIKey(configSetAPIKeyCmd, []string{"missing"}); err == nil {
+		t.Error("Expected an error for an unknown provider")
+	}
+}
+
+func TestConfigRemoveAPIKey(t *testing.T) {
+	setupTestHome(t)
+	resetFlags(t, configRemoveAPIKeyCmd, "yes", "purge-snapshots")
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.SetActiveProvider(glmProvider); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.SaveConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+	settingsPath := defaultSettingsPath()
+	if err := generateClaudeSettings(cfg, settingsPath, false, false, true); err != nil {
+		t.Fatal(err)
+	}
+
+	// Declining the prompt keeps the key
+	withEmptyStdin(t)
+	if err := runConfigRemoveAPIKey(configRemoveAPIKeyCmd, []string{glmProvider}); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err = config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Providers[glmProvider].Token == "" {
+		t.Fatal("Key should be kept when the prompt is declined")
+	}
+
+	_ = configRemoveAPIKeyCmd.Flags().Set("yes", "true")
+	_ = configRemoveAPIKeyCmd.Flags().Set("purge-snapshots", "true")
+	if err := runConfigRemoveAPIKey(configRemoveAPIKeyCmd, []string{glmProvider}); err != nil {
+		t.Fatalf("remove-api-key failed: %v", err)
+	}
+
+	cfg, err = config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token := cfg.Providers[glmProvider].Token; token != "" {
+		t.Errorf("Expected the key to be removed, got %q", token)
+	}
+
+	settings, err := LoadSettings(settingsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := settings.Env[config.EnvAuthToken]; ok {
+		t.Error("Expected ANTHROPIC_AUTH_TOKEN to be dropped from settings")
+	}
+
+	// The switch to glm snapshotted settings holding the key; it must be scrubbed
+	snapshotsDir := snapshotsDirFor(settingsPath)
+	snapshots, err := ListSnapshots(snapshotsDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range snapshots {
+		data, err := os.ReadFile(filepath.Join(snapshotsDir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(data), "glm-test-token") {
+			t.Errorf("Snapshot %s still contains the key", name)
+		}
+	}
+
+	if err := runConfigRemoveAPIKey(configRemoveAPIKeyCmd, []string{glmProvider}); err == nil {
+		t.Error("Expected an error when the provider has no key")
+	}
+}
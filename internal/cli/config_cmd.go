@@ -0,0 +1,349 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/vanducng/cflip/internal/config"
+)
+
+// configCmd groups commands that manage the cflip config file
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Manage the cflip configuration",
+	Long: `Manage providers and settings stored in the cflip config file without
+editing it by hand. The file lives in ~/.config/cflip (or
+$XDG_CONFIG_HOME/cflip), or in ~/.cflip on macOS, on Windows and when an
+older config is still there; cflip status shows which one is used.`,
+}
+
+// Auth methods accepted by add-provider --method
+const (
+	methodAPIKey       = "api_key"
+	methodSubscription = "subscription"
+)
+
+// configAddProviderCmd registers a custom provider
+var configAddProviderCmd = &cobra.Command{
+	Use:   "add-provider <name>",
+	Short: "Register a custom provider",
+	Long: `Register a custom Anthropic-compatible provider. The API token is asked
+for the first time you switch to the provider.
+
+Model mappings are given as category=model pairs, for example:
+  cflip config add-provider litellm --base-url http://localhost:4000 \
+    --models haiku=claude-haiku,sonnet=claude-sonnet
+
+--method subscription is for gateways in front of the Claude subscription:
+no API key is stored or asked for, and Claude Code signs in itself and
+sends that login to the base URL.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runConfigAddProvider,
+}
+
+// configRemoveProviderCmd deletes a provider
+var configRemoveProviderCmd = &cobra.Command{
+	Use:   "remove-provider <name>",
+	Short: "Remove a provider",
+	Long: `Remove a provider, its API key, its model mappings and the profiles
+that use it from the configuration. The active provider can only be removed
+together with --switch-to, which switches to another provider first, the
+same way cflip switch does, hooks and prompts included.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeProviderNames,
+	RunE:              runConfigRemoveProvider,
+}
+
+// configShowCmd prints the configuration
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show the cflip configuration",
+	Long: `Show the active provider configuration. API keys are always redacted so
+the output can be pasted into bug reports.`,
+	Args: cobra.NoArgs,
+	RunE: runConfigShow,
+}
+
+func init() {
+	configAddProviderCmd.Flags().String("display-name", "", "Name shown in lists and menus")
+	configAddProviderCmd.Flags().String("base-url", "", "Anthropic-compatible API base URL (required)")
+	configAddProviderCmd.Flags().String("auth-header", "", "Header for the token: authorization or x-api-key")
+	configAddProviderCmd.Flags().String("models", "", "Comma-separated category=model mappings")
+	configAddProviderCmd.Flags().String("method", "", "Auth method: api_key (default) or subscription")
+	configAddProviderCmd.Flags().Bool("force", false, "Overwrite an existing provider")
+
+	configRemoveProviderCmd.Flags().String("switch-to", "", "Provider to activate when removing the active one")
+
+	_ = configAddProviderCmd.RegisterFlagCompletionFunc("models", completeModelMappings)
+	_ = configAddProviderCmd.RegisterFlagCompletionFunc("method", fixedCompletion(methodAPIKey, methodSubscription))
+	_ = configAddProviderCmd.RegisterFlagCompletionFunc("auth-header",
+		fixedCompletion(config.AuthHeaderAuthorization, config.AuthHeaderAPIKey))
+	_ = configRemoveProviderCmd.RegisterFlagCompletionFunc("switch-to", completeProviderNames)
+
+	configShowCmd.Flags().BoolP("json", "j", false, "Output in JSON format")
+	configShowCmd.Flags().Bool("models", false, "Include model mappings")
+	configShowCmd.Flags().Bool("all", false, "Include every provider, not only the active one")
+
+	configCmd.AddCommand(configShowCmd)
+	configCmd.AddCommand(configAddProviderCmd)
+	configCmd.AddCommand(configRemoveProviderCmd)
+}
+
+// NewConfigCmd exports the config command
+func NewConfigCmd() *cobra.Command {
+	return configCmd
+}
+
+// redactedValue replaces secrets in config show output
+const redactedValue = "***"
+
+// configShowOutput is the JSON document printed by config show --json
+type configShowOutput struct {
+	ConfigPath string                        `json:"configPath"`
+	Provider   string                        `json:"provider"`
+	Providers  map[string]providerShowOutput `json:"providers"`
+}
+
+// providerShowOutput describes one provider in config show output
+type providerShowOutput struct {
+	DisplayName string            `json:"displayName,omitempty"`
+	Token       string            `json:"token,omitempty"`
+	BaseURL     string            `json:"baseURL,omitempty"`
+	AuthHeader  string            `json:"authHeader,omitempty"`
+	AuthMode    string            `json:"authMode,omitempty"`
+	ModelMap    map[string]string `json:"modelMap,omitempty"`
+}
+
+func runConfigShow(cmd *cobra.Command, args []string) error {
+	jsonOutput, _ := cmd.Flags().GetBool("json")
+	showModels, _ := cmd.Flags().GetBool("models")
+	showAll, _ := cmd.Flags().GetBool("all")
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	output := buildConfigShowOutput(cfg, showModels, showAll)
+
+	if jsonOutput {
+		data, err := json.MarshalIndent(output, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal configuration: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	fmt.Printf("Config file: %s\n", output.ConfigPath)
+	fmt.Printf("Active provider: %s\n", output.Provider)
+	for _, name := range sortedProviderNames(cfg) {
+		provider, exists := output.Providers[name]
+		if !exists {
+			continue
+		}
+		fmt.Printf("\n[%s]\n", name)
+		printShowField("display name", provider.DisplayName)
+		printShowField("token", provider.Token)
+		printShowField("base URL", provider.BaseURL)
+		printShowField("auth header", provider.AuthHeader)
+		printShowField("auth mode", provider.AuthMode)
+		for _, category := range config.ModelCategories {
+			printShowField(category, provider.ModelMap[category])
+		}
+	}
+
+	return nil
+}
+
+// buildConfigShowOutput converts the config into redacted show output
+func buildConfigShowOutput(cfg *config.Config, showModels, showAll bool) configShowOutput {
+	output := configShowOutput{
+		ConfigPath: config.GetConfigPath(),
+		Provider:   cfg.Provider,
+		Providers:  make(map[string]providerShowOutput),
+	}
+
+	for name, provider := range cfg.Providers {
+		if !showAll && name != cfg.Provider {
+			continue
+		}
+
+		shown := providerShowOutput{
+			DisplayName: provider.DisplayName,
+			BaseURL:     provider.BaseURL,
+			AuthHeader:  provider.AuthHeader,
+			AuthMode:    provider.AuthMode,
+		}
+		if provider.Token != "" {
+			shown.Token = redactedValue
+		}
+		if showModels {
+			shown.ModelMap = provider.ModelMap
+		}
+		output.Providers[name] = shown
+	}
+
+	return output
+}
+
+// printShowField prints a labelled value when it is set
+func printShowField(label, value string) {
+	if value != "" {
+		fmt.Printf("  %s: %s\n", label, value)
+	}
+}
+
+func runConfigAddProvider(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	quiet, _ := cmd.Flags().GetBool("quiet")
+	force, _ := cmd.Flags().GetBool("force")
+	displayName, _ := cmd.Flags().GetString("display-name")
+	baseURL, _ := cmd.Flags().GetString("base-url")
+	authHeader, _ := cmd.Flags().GetString("auth-header")
+	models, _ := cmd.Flags().GetString("models")
+	method, _ := cmd.Flags().GetString("method")
+
+	modelMap, err := parseModelMappings(models)
+	if err != nil {
+		return err
+	}
+
+	var authMode string
+	switch method {
+	case "":
+	case methodAPIKey:
+		authMode = config.AuthModeAPI
+	case methodSubscription:
+		if authHeader != "" {
+			return fmt.Errorf("--auth-header has no effect with --method %s, which sends no API key", methodSubscription)
+		}
+		authMode = config.AuthModeSubscription
+	default:
+		return fmt.Errorf("invalid --method '%s' (use %s or %s)", method, methodAPIKey, methodSubscription)
+	}
+
+	if err := addProvider(name, config.ProviderConfig{
+		DisplayName: displayName,
+		BaseURL:     baseURL,
+		AuthHeader:  strings.ToLower(authHeader),
+		AuthMode:    authMode,
+		ModelMap:    modelMap,
+	}, force); err != nil {
+		return err
+	}
+
+	if !quiet {
+		fmt.Printf("%s Added provider %s\n", checkMark(), name)
+	}
+	return nil
+}
+
+// addProvider validates and saves a new provider. An existing provider is
+// only replaced when force is set.
+func addProvider(name string, provider config.ProviderConfig, force bool) error {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	if _, exists := cfg.Providers[name]; exists && !force {
+		return fmt.Errorf("provider '%s' already exists (use --force to overwrite)", name)
+	}
+
+	cfg.SetProviderConfig(name, provider)
+
+	if err := cfg.ValidateProvider(name); err != nil {
+		return err
+	}
+
+	if err := config.SaveConfig(cfg); err != nil {
+		return fmt.Errorf("failed to save configuration: %w", err)
+	}
+	return nil
+}
+
+func runConfigRemoveProvider(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	verbose, _ := cmd.Flags().GetBool("verbose")
+	quiet, _ := cmd.Flags().GetBool("quiet")
+	switchTo, _ := cmd.Flags().GetString("switch-to")
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	// Check the provider can be removed before looking at --switch-to
+	if _, exists := cfg.Providers[name]; !exists {
+		return fmt.Errorf("provider '%s' not found", name)
+	}
+	if !cfg.IsExternal(name) {
+		return fmt.Errorf("provider '%s' is built in and cannot be removed", name)
+	}
+
+	active := cfg.Provider == name
+	switch {
+	case active && switchTo == "":
+		return fmt.Errorf("provider '%s' is active; use --switch-to <provider> to activate another one first", name)
+	case !active && switchTo != "":
+		return fmt.Errorf("--switch-to only applies when removing the active provider; '%s' is not active", name)
+	}
+
+	if switchTo != "" {
+		target, err := cfg.ResolveAlias(switchTo)
+		if err != nil {
+			return err
+		}
+		if target == name {
+			return fmt.Errorf("cannot switch to the provider being removed")
+		}
+		if err := performSwitch(cmd.Context(), cfg, target, switchOptions{
+			modelsMode:   modelsClear,
+			settingsPath: resolveSettingsPath(cmd),
+			verbose:      verbose,
+			quiet:        quiet,
+		}); err != nil {
+			return err
+		}
+	}
+
+	profiles := cfg.ProfilesUsing(name)
+	if err := cfg.RemoveProvider(name); err != nil {
+		return err
+	}
+
+	if err := config.SaveConfig(cfg); err != nil {
+		return fmt.Errorf("failed to save configuration: %w", err)
+	}
+
+	if !quiet {
+		fmt.Printf("%s Removed provider %s\n", checkMark(), name)
+		if len(profiles) > 0 {
+			fmt.Printf("%s Removed profiles using it: %s\n", checkMark(), strings.Join(profiles, ", "))
+		}
+	}
+	return nil
+}
+
+// parseModelMappings parses "category=model" pairs separated by commas
+func parseModelMappings(value string) (map[string]string, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+
+	modelMap := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		category, model, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		category = strings.TrimSpace(category)
+		model = strings.TrimSpace(model)
+		if !ok || category == "" || model == "" {
+			return nil, fmt.Errorf("invalid model mapping '%s' (expected category=model)", pair)
+		}
+		modelMap[category] = model
+	}
+
+	return modelMap, nil
+}
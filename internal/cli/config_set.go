@@ -0,0 +1,169 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/vanducng/cflip/internal/config"
+)
+
+// configSetBaseURLCmd changes the base URL of a provider
+var configSetBaseURLCmd = &cobra.Command{
+	Use:               "set-base-url <provider> <url>",
+	Short:             "Set the base URL of a provider",
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeProviderNames,
+	RunE:              runConfigSetBaseURL,
+}
+
+// configSetAuthHeaderCmd changes the header a provider token is sent in
+var configSetAuthHeaderCmd = &cobra.Command{
+	Use:   "set-auth-header <provider> <header>",
+	Short: "Set the header a provider's token is sent in",
+	Long: `Set the header the provider token is sent in: authorization (a bearer
+token in ANTHROPIC_AUTH_TOKEN) or x-api-key (ANTHROPIC_API_KEY).`,
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeProviderThenValues(config.AuthHeaderAuthorization, config.AuthHeaderAPIKey),
+	RunE:              runConfigSetAuthHeader,
+}
+
+// configAddModelCmd maps a model category of a provider to a model
+var configAddModelCmd = &cobra.Command{
+	Use:   "add-model <provider> <category> <model>",
+	Short: "Map a model category of a provider to a model",
+	Long: `Map one of the Claude Code model categories (haiku, sonnet, opus) of a
+provider to one of its models, for example:
+  cflip config add-model glm sonnet glm-4.6`,
+	Args:              cobra.ExactArgs(3),
+	ValidArgsFunction: completeProviderThenValues(config.ModelCategories...),
+	RunE:              runConfigAddModel,
+}
+
+// configRemoveModelCmd deletes a model mapping of a provider
+var configRemoveModelCmd = &cobra.Command{
+	Use:   "remove-model <provider> <category>",
+	Short: "Remove a model mapping from a provider",
+	Long: `Remove the model mapped to a category. Mappings of the active provider are
+in use by Claude Code and are only removed with --force.`,
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeProviderThenValues(config.ModelCategories...),
+	RunE:              runConfigRemoveModel,
+}
+
+func init() {
+	configSetBaseURLCmd.Flags().String("settings-path", "", "Claude settings file to write (default ~/.claude/settings.json)")
+	configSetAuthHeaderCmd.Flags().String("settings-path", "", "Claude settings file to write (default ~/.claude/settings.json)")
+
+	configAddModelCmd.Flags().Bool("force", false, "Replace an existing mapping for the category")
+	configAddModelCmd.Flags().String("settings-path", "", "Claude settings file to write (default ~/.claude/settings.json)")
+	configRemoveModelCmd.Flags().Bool("force", false, "Remove a mapping of the active provider")
+	configRemoveModelCmd.Flags().String("settings-path", "", "Claude settings file to write (default ~/.claude/settings.json)")
+
+	configCmd.AddCommand(configSetBaseURLCmd)
+	configCmd.AddCommand(configSetAuthHeaderCmd)
+	configCmd.AddCommand(configAddModelCmd)
+	configCmd.AddCommand(configRemoveModelCmd)
+}
+
+func runConfigSetBaseURL(cmd *cobra.Command, args []string) error {
+	name, baseURL := args[0], strings.TrimSpace(args[1])
+
+	if err := config.ValidateBaseURL(baseURL); err != nil {
+		return err
+	}
+
+	return updateProvider(cmd, name, func(provider *config.ProviderConfig) {
+		provider.BaseURL = baseURL
+	}, fmt.Sprintf("Set base URL for %s to %s", name, baseURL))
+}
+
+func runConfigSetAuthHeader(cmd *cobra.Command, args []string) error {
+	name, header := args[0], strings.ToLower(strings.TrimSpace(args[1]))
+
+	return updateProvider(cmd, name, func(provider *config.ProviderConfig) {
+		provider.AuthHeader = header
+	}, fmt.Sprintf("Set auth header for %s to %s", name, header))
+}
+
+func runConfigAddModel(cmd *cobra.Command, args []string) error {
+	name, category, model := args[0], strings.ToLower(args[1]), strings.TrimSpace(args[2])
+	force, _ := cmd.Flags().GetBool("force")
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if existing, ok := cfg.Providers[name].ModelMap[category]; ok && !force {
+		return fmt.Errorf("%s already maps %s to %s (use --force to replace it)", name, category, existing)
+	}
+
+	return updateProvider(cmd, name, func(provider *config.ProviderConfig) {
+		if provider.ModelMap == nil {
+			provider.ModelMap = make(map[string]string)
+		}
+		provider.ModelMap[category] = model
+	}, fmt.Sprintf("Mapped %s %s to %s", name, category, model))
+}
+
+func runConfigRemoveModel(cmd *cobra.Command, args []string) error {
+	name, category := args[0], strings.ToLower(args[1])
+	force, _ := cmd.Flags().GetBool("force")
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if _, exists := cfg.Providers[name]; !exists {
+		return fmt.Errorf("provider '%s' not found", name)
+	}
+	if _, ok := cfg.Providers[name].ModelMap[category]; !ok {
+		return fmt.Errorf("%s has no model mapped to %s", name, category)
+	}
+	if cfg.Provider == name && !force {
+		return fmt.Errorf("%s is the active provider and its %s mapping is in use (use --force to remove it)", name, category)
+	}
+
+	return updateProvider(cmd, name, func(provider *config.ProviderConfig) {
+		delete(provider.ModelMap, category)
+	}, fmt.Sprintf("Removed %s mapping from %s", category, name))
+}
+
+// updateProvider applies change to an existing provider, validates and saves
+// the config, and regenerates Claude settings when the provider is active
+func updateProvider(cmd *cobra.Command, name string, change func(*config.ProviderConfig), success string) error {
+	verbose, _ := cmd.Flags().GetBool("verbose")
+	quiet, _ := cmd.Flags().GetBool("quiet")
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	provider, exists := cfg.Providers[name]
+	if !exists {
+		return fmt.Errorf("provider '%s' not found", name)
+	}
+
+	change(&provider)
+	cfg.SetProviderConfig(name, provider)
+
+	if err := cfg.ValidateProvider(name); err != nil {
+		return err
+	}
+
+	if err := config.SaveConfig(cfg); err != nil {
+		return fmt.Errorf("failed to save configuration: %w", err)
+	}
+
+	if cfg.Provider == name {
+		if err := generateClaudeSettings(cfg, resolveSettingsPath(cmd), false, verbose, quiet); err != nil {
+			return fmt.Errorf("failed to generate Claude settings: %w", err)
+		}
+	}
+
+	if !quiet {
+		fmt.Printf("%s %s\n", checkMark(), success)
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/vanducng/cflip/internal/config"
+)
+
+func TestConfigSetBaseURL(t *testing.T) {
+	setupTestHome(t)
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.SetActiveProvider(glmProvider); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.SaveConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runConfigSetBaseURL(configSetBaseURLCmd, []string{glmProvider, "https://open.bigmodel.cn/api/anthropic"}); err != nil {
+		t.Fatalf("set-base-url failed: %v", err)
+	}
+
+	cfg, err = config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.Providers[glmProvider].BaseURL; got != "https://open.bigmodel.cn/api/anthropic" {
+		t.Errorf("Expected updated base URL, got %s", got)
+	}
+
+	settings, err := LoadSettings(defaultSettingsPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := settings.Env[config.EnvBaseURL]; got != "https://open.bigmodel.cn/api/anthropic" {
+		t.Errorf("Expected settings to be regenerated for the active provider, got %v", got)
+	}
+}
+
+func TestConfigSetBaseURLRejectsBadURL(t *testing.T) {
+	setupTestHome(t)
+
+	for _, url := range []string{"not a url", "ftp://example.com", "https://"} {
+		if err := runConfigSetBaseURL(configSetBaseURLCmd, []string{glmProvider, url}); err == nil {
+			t.Errorf("Expected %q to be rejected", url)
+		}
+	}
+
+	if err := runConfigSetBaseURL(configSetBaseURLCmd, []string{"missing", "https://example.com"}); err == nil {
+		t.Error("Expected an error for an unknown provider")
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.Providers[glmProvider].BaseURL; got != "https://api.z.ai/api/anthropic" {
+		t.Errorf("Rejected URL must not be saved, got %s", got)
+	}
+}
+
+func TestConfigSetAuthHeader(t *testing.T) {
+	setupTestHome(t)
+
+	if err := runConfigSetAuthHeader(configSetAuthHeaderCmd, []string{glmProvider, "X-API-Key"}); err != nil {
+		t.Fatalf("set-auth-header failed: %v", err)
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.Providers[glmProvider].AuthHeader; got != config.AuthHeaderAPIKey {
+		t.Errorf("Expected x-api-key, got %s", got)
+	}
+
+	if err := runConfigSetAuthHeader(configSetAuthHeaderCmd, []string{glmProvider, "bearer"}); err == nil {
+		t.Error("Expected an unsupported header to be rejected")
+	}
+}
+
+func TestConfigAddRemoveModel(t *testing.T) {
+	setupTestHome(t)
+	resetFlags(t, configAddModelCmd, "force")
+	resetFlags(t, configRemoveModelCmd, "force")
+
+	if err := runConfigAddModel(configAddModelCmd, []string{glmProvider, "sonnet", "glm-4.6"}); err != nil {
+		t.Fatalf("add-model failed: %v", err)
+	}
+
+	// A second mapping for the same category is a duplicate
+	if err := runConfigAddModel(configAddModelCmd, []string{glmProvider, "sonnet", "glm-4.5"}); err == nil {
+		t.Error("Expected a duplicate mapping to be rejected")
+	}
+	_ = configAddModelCmd.Flags().Set("force", "true")
+	if err := runConfigAddModel(configAddModelCmd, []string{glmProvider, "sonnet", "glm-4.5"}); err != nil {
+		t.Errorf("Expected --force to replace the mapping: %v", err)
+	}
+
+	if err := runConfigAddModel(configAddModelCmd, []string{glmProvider, "gigantic", "glm-4.6"}); err == nil {
+		t.Error("Expected an unknown category to be rejected")
+	}
+
+	// Mappings of the active provider are guarded
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.Providers[glmProvider].ModelMap["sonnet"]; got != "glm-4.5" {
+		t.Errorf("Expected sonnet mapped to glm-4.5, got %s", got)
+	}
+	if err := cfg.SetActiveProvider(glmProvider); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.SaveConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runConfigRemoveModel(configRemoveModelCmd, []string{glmProvider, "sonnet"}); err == nil {
+		t.Error("Expected removing an active mapping without --force to fail")
+	}
+	_ = configRemoveModelCmd.Flags().Set("force", "true")
+	if err := runConfigRemoveModel(configRemoveModelCmd, []string{glmProvider, "sonnet"}); err != nil {
+		t.Fatalf("remove-model --force failed: %v", err)
+	}
+
+	cfg, err = config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cfg.Providers[glmProvider].ModelMap["sonnet"]; ok {
+		t.Error("Expected the sonnet mapping to be removed")
+	}
+
+	settings, err := LoadSettings(defaultSettingsPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := settings.Env[config.EnvSonnetModel]; ok {
+		t.Error("Expected the sonnet model to be removed from settings")
+	}
+}
@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/spf13/cobra"
+	"github.com/vanducng/cflip/internal/config"
+)
+
+// currentCmd prints the active provider name and nothing else
+var currentCmd = &cobra.Command{
+	Use:     "current",
+	Aliases: []string{"which"},
+	Short:   "Print the active provider name",
+	Long: `Print only the name of the active provider, for shell prompts and scripts.
+With --model, print the model the active provider maps to a category instead.
+
+--format takes a Go template with .Provider, .DisplayName, .BaseURL and
+.Models, for example '{{.Provider}}/{{.Models.sonnet}}'. Only the config
+file is read; nothing is created or written.`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         runCurrent,
+}
+
+func init() {
+	currentCmd.Flags().String("model", "", "Print the model mapped to this category (haiku, sonnet or opus)")
+	currentCmd.Flags().String("format", "", "Print using a Go template, e.g. '{{.Provider}}/{{.Models.sonnet}}'")
+	_ = currentCmd.RegisterFlagCompletionFunc("model", fixedCompletion(config.ModelCategories...))
+}
+
+// currentInfo is the data available to current --format
+type currentInfo struct {
+	Provider    string
+	DisplayName string
+	BaseURL     string
+	Models      map[string]string
+}
+
+// NewCurrentCmd exports the current command
+func NewCurrentCmd() *cobra.Command {
+	return currentCmd
+}
+
+func runCurrent(cmd *cobra.Command, args []string) error {
+	category, _ := cmd.Flags().GetString("model")
+	format, _ := cmd.Flags().GetString("format")
+
+	if format != "" && cmd.Flags().Changed("model") {
+		return fmt.Errorf("--format cannot be combined with --model")
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if cfg.Provider == "" {
+		return fmt.Errorf("no active provider configured; run 'cflip switch'")
+	}
+	provider, err := cfg.GetActiveProvider()
+	if err != nil {
+		return err
+	}
+
+	if format != "" {
+		return printCurrentFormat(format, cfg.Provider, *provider)
+	}
+
+	if !cmd.Flags().Changed("model") {
+		fmt.Println(cfg.Provider)
+		return nil
+	}
+
+	category = strings.ToLower(category)
+	if _, ok := config.CategoryEnvKey(category); !ok {
+		return fmt.Errorf("unknown model category '%s' (use %s)", category, strings.Join(config.ModelCategories, ", "))
+	}
+
+	model, ok := provider.ModelMap[category]
+	if !ok {
+		return fmt.Errorf("%s has no model mapped to %s", cfg.Provider, category)
+	}
+	fmt.Println(model)
+	return nil
+}
+
+// printCurrentFormat prints the active provider through a Go template.
+// Unmapped model categories print as empty strings.
+func printCurrentFormat(format, providerName string, provider config.ProviderConfig) error {
+	tmpl, err := template.New("current").Option("missingkey=zero").Parse(format)
+	if err != nil {
+		return fmt.Errorf("invalid --format template: %w", err)
+	}
+
+	displayName, _ := getProviderDisplayInfo(providerName, provider)
+	info := currentInfo{
+		Provider:    providerName,
+		DisplayName: displayName,
+		BaseURL:     provider.BaseURL,
+		Models:      make(map[string]string, len(provider.ModelMap)),
+	}
+	for category, model := range provider.ModelMap {
+		info.Models[category] = model
+	}
+
+	if err := tmpl.Execute(os.Stdout, info); err != nil {
+		return fmt.Errorf("failed to render --format template: %w", err)
+	}
+	fmt.Println()
+	return nil
+}
@@ -0,0 +1,120 @@
+package cli
+
+import (
+	"os"
+	"testing"
+
+	"github.com/vanducng/cflip/internal/config"
+)
+
+func TestCurrent(t *testing.T) {
+	setupTestHome(t)
+	resetFlags(t, currentCmd, "model", "format")
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider := cfg.Providers[glmProvider]
+	provider.ModelMap = map[string]string{"sonnet": "glm-4.6"}
+	cfg.SetProviderConfig(glmProvider, provider)
+	if err := cfg.SetActiveProvider(glmProvider); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.SaveConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	out := captureStdout(t, func() error {
+		return runCurrent(currentCmd, nil)
+	})
+	if out != "glm\n" {
+		t.Errorf("Expected exactly %q, got %q", "glm\n", out)
+	}
+
+	_ = currentCmd.Flags().Set("model", "sonnet")
+	out = captureStdout(t, func() error {
+		return runCurrent(currentCmd, nil)
+	})
+	if out != "glm-4.6\n" {
+		t.Errorf("Expected exactly %q, got %q", "glm-4.6\n", out)
+	}
+
+	for _, category := range []string{"turbo", "opus"} {
+		_ = currentCmd.Flags().Set("model", category)
+		if err := runCurrent(currentCmd, nil); err == nil {
+			t.Errorf("Expected --model %s to fail", category)
+		}
+	}
+}
+
+func TestCurrentFormat(t *testing.T) {
+	setupTestHome(t)
+	resetFlags(t, currentCmd, "model", "format")
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider := cfg.Providers[glmProvider]
+	provider.ModelMap = map[string]string{"sonnet": "glm-4.6"}
+	cfg.SetProviderConfig(glmProvider, provider)
+	if err := cfg.SetActiveProvider(glmProvider); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.SaveConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]string{
+		"{{.Provider}}/{{.Models.sonnet}}": "glm/glm-4.6\n",
+		"{{.Provider}}/{{.Models.opus}}":   "glm/\n",
+	}
+	for format, want := range tests {
+		_ = currentCmd.Flags().Set("format", format)
+		out := captureStdout(t, func() error {
+			return runCurrent(currentCmd, nil)
+		})
+		if out != want {
+			t.Errorf("Format %q: expected %q, got %q", format, want, out)
+		}
+	}
+
+	_ = currentCmd.Flags().Set("format", "{{.Provider")
+	if err := runCurrent(currentCmd, nil); err == nil {
+		t.Error("Expected an invalid template to fail")
+	}
+}
+
+func TestCurrentCreatesNoFiles(t *testing.T) {
+	isolateHome(t)
+	resetFlags(t, currentCmd, "model", "format")
+
+	out := captureStdout(t, func() error {
+		return runCurrent(currentCmd, nil)
+	})
+	if out != "anthropic\n" {
+		t.Errorf("Expected the default provider, got %q", out)
+	}
+	if _, err := os.Stat(config.GetConfigPath()); !os.IsNotExist(err) {
+		t.Errorf("Expected no config file to be created, got %v", err)
+	}
+}
+
+func TestCurrentWithoutProvider(t *testing.T) {
+	setupTestHome(t)
+	resetFlags(t, currentCmd, "model", "format")
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Provider = ""
+	if err := config.SaveConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runCurrent(currentCmd, nil); err == nil {
+		t.Error("Expected current to fail without an active provider")
+	}
+}
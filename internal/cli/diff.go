@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/vanducng/cflip/internal/config"
+)
+
+// Kinds of settings changes
+const (
+	changeAdded   = "added"
+	changeRemoved = "removed"
+	changeChanged = "changed"
+)
+
+// settingsChange is a single difference between two settings files
+type settingsChange struct {
+	Kind string      `json:"kind"`
+	Key  string      `json:"key"`
+	Old  interface{} `json:"old,omitempty"`
+	New  interface{} `json:"new,omitempty"`
+}
+
+// diffSettings compares two settings structurally. Env keys are reported as
+// "env.<KEY>", other top-level fields by name.
+func diffSettings(from, to *ClaudeSettings) []settingsChange {
+	var changes []settingsChange
+
+	if from.Schema != to.Schema {
+		changes = append(changes, diffValue("$schema", optionalString(from.Schema), optionalString(to.Schema))...)
+	}
+
+	changes = append(changes, diffMaps("env.", from.Env, to.Env)...)
+	changes = append(changes, diffMaps("", from.AdditionalFields, to.AdditionalFields)...)
+
+	return changes
+}
+
+// diffMaps compares two maps key by key, sorted by key
+func diffMaps(prefix string, from, to map[string]interface{}) []settingsChange {
+	keys := make(map[string]bool)
+	for key := range from {
+		keys[key] = true
+	}
+	for key := range to {
+		keys[key] = true
+	}
+
+	sorted := make([]string, 0, len(keys))
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+	sort.Strings(sorted)
+
+	var changes []settingsChange
+	for _, key := range sorted {
+		changes = append(changes, diffValue(prefix+key, from[key], to[key])...)
+	}
+	return changes
+}
+
+// diffValue compares a single value, treating nil as absent
+func diffValue(key string, from, to interface{}) []settingsChange {
+	switch {
+	case from == nil && to == nil:
+		return nil
+	case from == nil:
+		return []settingsChange{{Kind: changeAdded, Key: key, New: to}}
+	case to == nil:
+		return []settingsChange{{Kind: changeRemoved, Key: key, Old: from}}
+	case !compareValues(from, to):
+		return []settingsChange{{Kind: changeChanged, Key: key, Old: from, New: to}}
+	}
+	return nil
+}
+
+// optionalString returns nil for an empty string so it diffs as absent
+func optionalString(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	return value
+}
+
+// printSettingsChanges prints changes with +/-/~ markers and secrets masked
+func printSettingsChanges(changes []settingsChange) {
+	for _, change := range changes {
+		switch change.Kind {
+		case changeAdded:
+			fmt.Printf("  + %s: %s\n", change.Key, displayValue(change.Key, change.New))
+		case changeRemoved:
+			fmt.Printf("  - %s: %s\n", change.Key, displayValue(change.Key, change.Old))
+		case changeChanged:
+			fmt.Printf("  ~ %s: %s → %s\n", change.Key,
+				displayValue(change.Key, change.Old), displayValue(change.Key, change.New))
+		}
+	}
+}
+
+// displayValue formats a value for diff output, masking secrets
+func displayValue(key string, value interface{}) string {
+	if s, ok := value.(string); ok {
+		if isSecretKey(key) {
+			return config.Redact(s)
+		}
+		return s
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(data)
+}
+
+// isSecretKey reports whether a settings key holds a credential
+func isSecretKey(key string) bool {
+	upper := strings.ToUpper(key)
+	return strings.Contains(upper, "TOKEN") || strings.Contains(upper, "API_KEY") || strings.Contains(upper, "SECRET")
+}
@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDiffSettings(t *testing.T) {
+	from := &ClaudeSettings{
+		Env: map[string]interface{}{
+			"ANTHROPIC_AUTH_TOKEN": "sk-ant-old-token",
+			"ANTHROPIC_BASE_URL":   "https://api.z.ai/api/anthropic",
+			"KEEP":                 "same",
+		},
+		AdditionalFields: map[string]interface{}{
+			"permissions": map[string]interface{}{"allow": []interface{}{"Bash(ls)"}},
+		},
+	}
+	to := &ClaudeSettings{
+		Env: map[string]interface{}{
+			"ANTHROPIC_AUTH_TOKEN": "sk-ant-new-token",
+			"KEEP":                 "same",
+			"API_TIMEOUT_MS":       "3000000",
+		},
+		AdditionalFields: map[string]interface{}{
+			"permissions": map[string]interface{}{"allow": []interface{}{"Bash(ls)", "Bash(cat)"}},
+		},
+	}
+
+	changes := diffSettings(from, to)
+
+	want := map[string]string{
+		"env.ANTHROPIC_AUTH_TOKEN": changeChanged,
+		"env.ANTHROPIC_BASE_URL":   changeRemoved,
+		"env.API_TIMEOUT_MS":       changeAdded,
+		"permissions":              changeChanged,
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("Expected %d changes, got %+v", len(want), changes)
+	}
+	for _, change := range changes {
+		if want[change.Key] != change.Kind {
+			t.Errorf("Unexpected change %+v", change)
+		}
+	}
+
+	if diffSettings(from, from) != nil {
+		t.Error("Identical settings should have no changes")
+	}
+}
+
+func TestDisplayValueMasksTokens(t *testing.T) {
+	got := displayValue("env.ANTHROPIC_AUTH_TOKEN", "sk-ant-secret-value")
+	if strings.Contains(got, "secret-val") || got != "sk-a*************ue" {
+		t.Errorf("Token not masked correctly: %s", got)
+	}
+
+	if got := displayValue("env.ANTHROPIC_BASE_URL", "https://example.com"); got != "https://example.com" {
+		t.Errorf("Non-secret value should be shown as is, got %s", got)
+	}
+}
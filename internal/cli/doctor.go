@@ -0,0 +1,209 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/vanducng/cflip/internal/config"
+)
+
+// doctorCmd represents the doctor command
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnose the cflip and Claude Code environment",
+	Long: `Inspect the environment cflip depends on: the cflip config, the Claude
+settings file, the snapshots directory, the claude binary and the active
+provider's API key. Every failed check prints a hint on how to fix it.
+
+doctor only reads files; it never changes anything.`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         runDoctor,
+}
+
+func init() {
+	doctorCmd.Flags().String("settings-path", "", "Claude settings file to check (default ~/.claude/settings.json)")
+}
+
+// NewDoctorCmd exports the doctor command
+func NewDoctorCmd() *cobra.Command {
+	return doctorCmd
+}
+
+// doctorCheck is the result of a single doctor check
+type doctorCheck struct {
+	Name   string
+	OK     bool
+	Detail string
+	Hint   string
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	if failed := printDoctorChecks(runDoctorChecks(resolveSettingsPath(cmd))); failed > 0 {
+		return fmt.Errorf("%d check(s) failed", failed)
+	}
+	return nil
+}
+
+// printDoctorChecks prints each check with its hint and returns the number that failed
+func printDoctorChecks(checks []doctorCheck) int {
+	failed := 0
+	for _, check := range checks {
+		if check.OK {
+			fmt.Printf("%s %s: %s\n", checkMark(), check.Name, check.Detail)
+			continue
+		}
+		failed++
+		fmt.Printf("%s %s: %s\n", crossMark(), check.Name, check.Detail)
+		if check.Hint != "" {
+			fmt.Printf("    hint: %s\n", check.Hint)
+		}
+	}
+	return failed
+}
+
+// runDoctorChecks runs every doctor check in display order
+func runDoctorChecks(settingsPath string) []doctorCheck {
+	configCheck, cfg := checkConfigFile()
+
+	return []doctorCheck{
+		configCheck,
+		checkSettingsFile(settingsPath),
+		checkDirWritable("snapshots directory", snapshotsDirFor(settingsPath)),
+		checkClaudeBinary(),
+		checkActiveAPIKey(cfg),
+	}
+}
+
+// checkConfigFile checks that the cflip config exists and parses. The
+// parsed config is returned for later checks, or nil when it is unusable.
+func checkConfigFile() (doctorCheck, *config.Config) {
+	path := config.GetConfigPath()
+	check := doctorCheck{Name: "cflip config"}
+
+	if _, err := os.Stat(path); err != nil {
+		check.Detail = fmt.Sprintf("%s not found", path)
+		check.Hint = "run cflip switch to create it"
+		return check, nil
+	}
+
+	cfg, err := config.LoadConfigFile(path)
+	if err != nil {
+		check.Detail = err.Error()
+		check.Hint = "fix the TOML syntax with cflip edit --cflip"
+		return check, nil
+	}
+
+	check.OK = true
+	check.Detail = path
+	return check, cfg
+}
+
+// checkSettingsFile checks that the Claude settings file exists and is valid JSON
+func checkSettingsFile(settingsPath string) doctorCheck {
+	check := doctorCheck{Name: "Claude settings"}
+
+	data, err := os.ReadFile(settingsPath)
+	if err != nil {
+		check.Detail = err.Error()
+		check.Hint = "run cflip switch to generate it"
+		return check
+	}
+
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		check.Detail = fmt.Sprintf("%s is not valid JSON: %v", settingsPath, err)
+		check.Hint = "fix the JSON with cflip edit or restore a snapshot with cflip snapshot restore --latest"
+		return check
+	}
+
+	check.OK = true
+	check.Detail = settingsPath
+	return check
+}
+
+// checkDirWritable checks that a directory, or the closest existing parent
+// it would be created in, is writable by the current user
+func checkDirWritable(name, dir string) doctorCheck {
+	check := doctorCheck{Name: name}
+
+	existing := dir
+	for {
+		info, err := os.Stat(existing)
+		if err == nil {
+			if !info.IsDir() {
+				check.Detail = fmt.Sprintf("%s is not a directory", existing)
+				check.Hint = "move the file out of the way"
+				return check
+			}
+			if info.Mode().Perm()&0200 == 0 {
+				check.Detail = fmt.Sprintf("%s is not writable", existing)
+				check.Hint = fmt.Sprintf("chmod u+w %s", existing)
+				return check
+			}
+			break
+		}
+
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			check.Detail = fmt.Sprintf("no existing parent for %s", dir)
+			return check
+		}
+		existing = parent
+	}
+
+	check.OK = true
+	check.Detail = dir
+	return check
+}
+
+// checkClaudeBinary checks that the claude binary is on PATH
+func checkClaudeBinary() doctorCheck {
+	check := doctorCheck{Name: "claude binary"}
+
+	path, err := exec.LookPath("claude")
+	if err != nil {
+		check.Detail = "claude not found on PATH"
+		check.Hint = "install Claude Code: npm install -g @anthropic-ai/claude-code"
+		return check
+	}
+
+	check.OK = true
+	check.Detail = path
+	return check
+}
+
+// checkActiveAPIKey checks that the active provider has the API key it needs
+func checkActiveAPIKey(cfg *config.Config) doctorCheck {
+	check := doctorCheck{Name: "API key"}
+
+	if cfg == nil {
+		check.Detail = "skipped, the cflip config could not be loaded"
+		return check
+	}
+
+	_, exists := cfg.Providers[cfg.Provider]
+	provider, fromEnv := cfg.ResolveProvider(cfg.Provider)
+	switch {
+	case !exists && cfg.IsExternal(cfg.Provider):
+		check.Detail = fmt.Sprintf("active provider '%s' is not configured", cfg.Provider)
+		check.Hint = "run cflip switch to pick a configured provider"
+	case cfg.NeedsAPIKey(cfg.Provider) && provider.Token == "":
+		check.Detail = fmt.Sprintf("no API key for %s", cfg.Provider)
+		check.Hint = "run cflip config set-api-key " + cfg.Provider
+	case !cfg.IsExternal(cfg.Provider) && !provider.UsesAPIKey():
+		check.OK = true
+		check.Detail = "not required for subscription auth"
+	case fromEnv:
+		check.OK = true
+		check.Detail = fmt.Sprintf("from %s", config.APIKeyEnvVar(cfg.Provider))
+	default:
+		check.OK = true
+		check.Detail = fmt.Sprintf("configured for %s", cfg.Provider)
+	}
+	return check
+}
@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// findDoctorCheck returns the named check from a doctor run
+func findDoctorCheck(t *testing.T, checks []doctorCheck, name string) doctorCheck {
+	t.Helper()
+
+	for _, check := range checks {
+		if check.Name == name {
+			return check
+		}
+	}
+	t.Fatalf("Check %q not run", name)
+	return doctorCheck{}
+}
+
+func TestDoctorMissingConfig(t *testing.T) {
+	home := isolateHome(t)
+
+	checks := runDoctorChecks(defaultSettingsPath())
+
+	if check := findDoctorCheck(t, checks, "cflip config"); check.OK {
+		t.Errorf("Expected the config check to fail, got %+v", check)
+	}
+	if check := findDoctorCheck(t, checks, "API key"); check.OK {
+		t.Errorf("Expected the API key check to be skipped as failed, got %+v", check)
+	}
+
+	// doctor must not create anything
+	if _, err := os.Stat(filepath.Join(home, ".cflip")); !os.IsNotExist(err) {
+		t.Error("doctor should not create the config directory")
+	}
+}
+
+func TestDoctorBrokenSettings(t *testing.T) {
+	setupTestHome(t)
+
+	settingsPath := defaultSettingsPath()
+	if err := os.MkdirAll(filepath.Dir(settingsPath), 0750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(settingsPath, []byte(`{"env": {`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	checks := runDoctorChecks(settingsPath)
+
+	if check := findDoctorCheck(t, checks, "cflip config"); !check.OK {
+		t.Errorf("Expected the config check to pass, got %+v", check)
+	}
+	if check := findDoctorCheck(t, checks, "Claude settings"); check.OK {
+		t.Errorf("Expected the settings check to fail, got %+v", check)
+	}
+	if check := findDoctorCheck(t, checks, "snapshots directory"); !check.OK {
+		t.Errorf("Expected the snapshots directory to be writable, got %+v", check)
+	}
+}
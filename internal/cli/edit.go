@@ -5,10 +5,11 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
-	"path/filepath"
 	"runtime"
+	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/vanducng/cflip/internal/config"
 )
 
 const (
@@ -32,14 +33,18 @@ func init() {
 	editCmd.Flags().BoolP("settings", "s", false, "Edit settings file (default)")
 	editCmd.Flags().BoolP("cflip", "c", false, "Edit cflip config file")
 	editCmd.Flags().BoolP("snapshot", "p", false, "List and manage snapshots")
+	_ = editCmd.Flags().MarkDeprecated("snapshot", "use 'cflip snapshot list' instead")
+	editCmd.Flags().String("settings-path", "", "Claude settings file to edit (default ~/.claude/settings.json)")
 }
 
 func runEdit(cmd *cobra.Command, args []string) error {
 	editCflip, _ := cmd.Flags().GetBool("cflip")
 	editSnapshot, _ := cmd.Flags().GetBool("snapshot")
 
+	settingsPath := resolveSettingsPath(cmd)
+
 	if editSnapshot {
-		return manageSnapshots()
+		return manageSnapshots(settingsPath)
 	}
 
 	if editCflip {
@@ -47,16 +52,41 @@ func runEdit(cmd *cobra.Command, args []string) error {
 	}
 
 	// Default: edit Claude settings
-	homeDir, _ := os.UserHomeDir()
-	settingsPath := filepath.Join(homeDir, ".claude", "settings.json")
-
 	// Check if file exists
 	if _, err := os.Stat(settingsPath); os.IsNotExist(err) {
 		return fmt.Errorf("settings file not found at %s", settingsPath)
 	}
 
-	// Get editor
-	editor := os.Getenv("EDITOR")
+	if err := openInEditor(settingsPath); err != nil {
+		return err
+	}
+
+	fmt.Printf("Settings file opened: %s\n", settingsPath)
+	return nil
+}
+
+// openInEditor opens a file in $EDITOR or the platform default editor
+func openInEditor(path string) error {
+	name, args := editorCommand(os.Getenv("EDITOR"), path)
+
+	// Launch editor with context
+	ctx := context.Background()
+	execCmd := exec.CommandContext(ctx, name, args...)
+	execCmd.Stdin = os.Stdin
+	execCmd.Stdout = os.Stdout
+	execCmd.Stderr = os.Stderr
+
+	if err := execCmd.Run(); err != nil {
+		return fmt.Errorf("failed to open editor: %w", err)
+	}
+
+	return nil
+}
+
+// editorCommand returns the program and arguments that open path in editor.
+// An editor value may carry its own arguments, such as "code --wait", and
+// falls back to the platform default when empty.
+func editorCommand(editor, path string) (string, []string) {
 	if editor == "" {
 		// Try common editors based on OS
 		switch runtime.GOOS {
@@ -69,83 +99,103 @@ func runEdit(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// Launch editor with context
-	ctx := context.Background()
-	var execCmd *exec.Cmd
 	if runtime.GOOS == darwinOS && editor == editorDarwin {
 		// On macOS, use 'open' with text editor mode
-		execCmd = exec.CommandContext(ctx, editor, "-t", settingsPath)
-	} else {
-		execCmd = exec.CommandContext(ctx, editor, settingsPath)
+		return editor, []string{"-t", path}
 	}
 
-	execCmd.Stdin = os.Stdin
-	execCmd.Stdout = os.Stdout
-	execCmd.Stderr = os.Stderr
-
-	if err := execCmd.Run(); err != nil {
-		return fmt.Errorf("failed to open editor: %w", err)
+	// An unquoted path with spaces names the editor itself
+	fields := []string{editor}
+	if _, err := os.Stat(editor); err != nil {
+		fields = splitCommandLine(editor, runtime.GOOS == windowsOS)
+	}
+	if len(fields) == 0 {
+		fields = []string{editor}
 	}
 
-	fmt.Printf("Settings file opened: %s\n", settingsPath)
-	return nil
+	return fields[0], append(fields[1:], path)
 }
 
-func editCflipConfig() error {
-	configPath := "internal/config/config.go"
-
-	// Get editor
-	editor := os.Getenv("EDITOR")
-	if editor == "" {
-		editor = editorDefault
+// splitCommandLine splits a command line into words, honoring single and
+// double quotes. Backslash escapes the next character except on Windows,
+// where it is the path separator.
+func splitCommandLine(line string, windows bool) []string {
+	var (
+		words   []string
+		current strings.Builder
+		inWord  bool
+		quote   rune
+		escaped bool
+	)
+
+	for _, r := range line {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\' && !windows && quote != '\'':
+			escaped = true
+			inWord = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t':
+			if inWord {
+				words = append(words, current.String())
+				current.Reset()
+				inWord = false
+			}
+		default:
+			current.WriteRune(r)
+			inWord = true
+		}
 	}
+	if inWord {
+		words = append(words, current.String())
+	}
+	return words
+}
 
-	// Launch editor with context
-	ctx := context.Background()
-	cmd := exec.CommandContext(ctx, editor, configPath)
-	cmd.Stdin = os.Stdin
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+// ensureCflipConfig returns the cflip config path, writing a default config if it doesn't exist
+func ensureCflipConfig() (string, error) {
+	configPath := config.GetConfigPath()
 
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to open editor: %w", err)
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		if err := config.SaveConfigFile(configPath, config.NewConfig()); err != nil {
+			return "", err
+		}
 	}
 
-	fmt.Printf("Config file opened: %s\n", configPath)
-	return nil
+	return configPath, nil
 }
 
-func manageSnapshots() error {
-	homeDir, _ := os.UserHomeDir()
-	snapshotsDir := filepath.Join(homeDir, ".claude", "snapshots")
-
-	// List snapshots
-	snapshots, err := ListSnapshots(snapshotsDir)
+func editCflipConfig() error {
+	configPath, err := ensureCflipConfig()
 	if err != nil {
-		return fmt.Errorf("failed to list snapshots: %w", err)
+		return err
 	}
 
-	if len(snapshots) == 0 {
-		fmt.Println("No snapshots found")
-		return nil
+	if err := openInEditor(configPath); err != nil {
+		return err
 	}
 
-	fmt.Println("Available snapshots:")
-	for i, snapshot := range snapshots {
-		// Extract provider and timestamp
-		parts := snapshot
-		if len(parts) > len("snapshot-") {
-			info := parts[9:] // Remove "snapshot-" prefix
-			if idx := findIndex(info, '-'); idx > 0 {
-				provider := info[:idx]
-				timestamp := info[idx+1 : len(info)-5] // Remove .json
-				fmt.Printf("  %d) %s - %s\n", i+1, provider, timestamp)
-			}
-		}
-	}
+	fmt.Printf("Config file opened: %s\n", configPath)
 
-	fmt.Printf("\nSnapshots directory: %s\n", snapshotsDir)
-	fmt.Println("Note: To restore a snapshot, manually copy the contents to ~/.claude/settings.json")
+	// Keep the user's edits but tell them if the file no longer parses
+	if _, err := config.LoadConfig(); err != nil {
+		return fmt.Errorf("config file is invalid after editing (your changes were kept): %w", err)
+	}
 
 	return nil
 }
+
+// manageSnapshots lists snapshots; kept for the deprecated edit --snapshot flag
+func manageSnapshots(settingsPath string) error {
+	return printSnapshotList(settingsPath, false)
+}
@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/vanducng/cflip/internal/config"
+)
+
+func TestEnsureCflipConfig(t *testing.T) {
+	isolateHome(t)
+
+	path, err := ensureCflipConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != config.GetConfigPath() {
+		t.Errorf("Expected %s, got %s", config.GetConfigPath(), path)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("Expected default config to be created: %v", err)
+	}
+}
+
+func TestEditCflipConfigReportsInvalidTOML(t *testing.T) {
+	isolateHome(t)
+
+	path, err := ensureCflipConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Simulate an editor that leaves broken TOML behind
+	t.Setenv("EDITOR", "true")
+	if err := os.WriteFile(path, []byte("provider = \n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := editCflipConfig(); err == nil {
+		t.Error("Expected an error for invalid TOML")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "provider = \n" {
+		t.Error("User edits should not be discarded")
+	}
+}
+
+func TestEditorCommand(t *testing.T) {
+	path := filepath.Join("home", "me", ".claude", "settings.json")
+
+	tests := []struct {
+		editor   string
+		wantName string
+		wantArgs []string
+	}{
+		{"vim", "vim", []string{path}},
+		{"code --wait", "code", []string{"--wait", path}},
+		{"  subl   -n  -w ", "subl", []string{"-n", "-w", path}},
+		{`"/opt/My Editor/bin/edit" --new-window`, "/opt/My Editor/bin/edit", []string{"--new-window", path}},
+		{`emacsclient -a '' -c`, "emacsclient", []string{"-a", "", "-c", path}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.editor, func(t *testing.T) {
+			name, args := editorCommand(tt.editor, path)
+			if name != tt.wantName || !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("editorCommand(%q) = %q %q, want %q %q", tt.editor, name, args, tt.wantName, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestEditorCommandPathWithSpaces(t *testing.T) {
+	// An existing editor path is used as is even when it is not quoted
+	editor := filepath.Join(t.TempDir(), "my editor")
+	if err := os.WriteFile(editor, nil, 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	name, args := editorCommand(editor, "settings.json")
+	if name != editor || len(args) != 1 {
+		t.Errorf("Expected %q to be run as is, got %q %q", editor, name, args)
+	}
+}
@@ -0,0 +1,31 @@
+//go:build windows
+
+package cli
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEditorCommandWindowsPaths(t *testing.T) {
+	path := `C:\Users\me\.claude\settings.json`
+
+	tests := []struct {
+		editor   string
+		wantName string
+		wantArgs []string
+	}{
+		{"", editorWindows, []string{path}},
+		{`C:\tools\vim.exe`, `C:\tools\vim.exe`, []string{path}},
+		{`"C:\Program Files\Notepad++\notepad++.exe" -multiInst`, `C:\Program Files\Notepad++\notepad++.exe`, []string{"-multiInst", path}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.editor, func(t *testing.T) {
+			name, args := editorCommand(tt.editor, path)
+			if name != tt.wantName || !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("editorCommand(%q) = %q %q, want %q %q", tt.editor, name, args, tt.wantName, tt.wantArgs)
+			}
+		})
+	}
+}
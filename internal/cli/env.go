@@ -0,0 +1,172 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/vanducng/cflip/internal/config"
+)
+
+// Shells env can print for
+const (
+	shellBash       = "bash"
+	shellZsh        = "zsh"
+	shellFish       = "fish"
+	shellPowerShell = "powershell"
+)
+
+// envCmd prints a provider's Claude Code env vars as shell commands
+var envCmd = &cobra.Command{
+	Use:   "env [provider]",
+	Short: "Print shell commands that set a provider's environment",
+	Long: `Print the env vars Claude Code reads for the active or named provider as
+shell commands, without touching the Claude settings file:
+
+  eval "$(cflip env glm)"
+
+Env vars cflip manages that the provider does not use are unset, so the
+output also switches away from another provider. --unset prints only the
+commands that clear them.
+
+Tokens are printed only when the output is not a terminal, as with eval,
+or with --show-secrets.`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeProviderNames,
+	SilenceUsage:      true,
+	RunE:              runEnv,
+}
+
+func init() {
+	envCmd.Flags().String("shell", "", "Shell syntax: bash, zsh, fish or powershell (default from $SHELL)")
+	envCmd.Flags().Bool("unset", false, "Print commands that unset every env var cflip manages")
+	envCmd.Flags().Bool("show-secrets", false, "Print tokens even when the output is a terminal")
+
+	_ = envCmd.RegisterFlagCompletionFunc("shell", fixedCompletion(shellBash, shellZsh, shellFish, shellPowerShell))
+}
+
+// NewEnvCmd exports the env command
+func NewEnvCmd() *cobra.Command {
+	return envCmd
+}
+
+func runEnv(cmd *cobra.Command, args []string) error {
+	shell, _ := cmd.Flags().GetString("shell")
+	unset, _ := cmd.Flags().GetBool("unset")
+	showSecrets, _ := cmd.Flags().GetBool("show-secrets")
+
+	if shell == "" {
+		shell = defaultShell()
+	}
+	shell = strings.ToLower(shell)
+	switch shell {
+	case shellBash, shellZsh, shellFish, shellPowerShell:
+	default:
+		return fmt.Errorf("unsupported shell '%s' (use %s, %s, %s or %s)", shell, shellBash, shellZsh, shellFish, shellPowerShell)
+	}
+
+	if unset && len(args) > 0 {
+		return fmt.Errorf("--unset cannot be combined with a provider name")
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	env := map[string]string{}
+	if !unset {
+		providerName := cfg.Provider
+		if len(args) == 1 {
+			if providerName, err = cfg.ResolveAlias(args[0]); err != nil {
+				return err
+			}
+		}
+		if env, err = cfg.GenerateSettingsPreview(providerName); err != nil {
+			return err
+		}
+	}
+
+	showSecrets = showSecrets || !isTerminalFile(os.Stdout)
+	fmt.Print(formatEnvScript(shell, env, cfg.OwnedEnvKeys(), showSecrets))
+	return nil
+}
+
+// formatEnvScript returns shell commands that unset the owned keys missing
+// from env and set the rest. Hidden secrets are replaced by a comment.
+func formatEnvScript(shell string, env map[string]string, owned []string, showSecrets bool) string {
+	var b strings.Builder
+
+	for _, key := range owned {
+		if _, ok := env[key]; !ok {
+			b.WriteString(unsetCommand(shell, key))
+			b.WriteString("\n")
+		}
+	}
+
+	keys := make([]string, 0, len(env))
+	for key := range env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if isSecretKey(key) && !showSecrets {
+			fmt.Fprintf(&b, "# %s hidden on a terminal; pipe the output or pass --show-secrets\n", key)
+			continue
+		}
+		b.WriteString(setCommand(shell, key, env[key]))
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+// setCommand returns the command that sets key to value in shell
+func setCommand(shell, key, value string) string {
+	switch shell {
+	case shellFish:
+		return fmt.Sprintf("set -gx %s %s", key, fishQuote(value))
+	case shellPowerShell:
+		return fmt.Sprintf("$env:%s = '%s'", key, strings.ReplaceAll(value, "'", "''"))
+	default:
+		return fmt.Sprintf("export %s='%s'", key, strings.ReplaceAll(value, "'", `'\''`))
+	}
+}
+
+// unsetCommand returns the command that removes key in shell
+func unsetCommand(shell, key string) string {
+	switch shell {
+	case shellFish:
+		return "set -e " + key
+	case shellPowerShell:
+		return fmt.Sprintf("Remove-Item Env:%s -ErrorAction SilentlyContinue", key)
+	default:
+		return "unset " + key
+	}
+}
+
+// fishQuote single-quotes value for fish, where \ and ' are escaped inside quotes
+func fishQuote(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	return "'" + strings.ReplaceAll(value, "'", `\'`) + "'"
+}
+
+// defaultShell picks the shell syntax from $SHELL, falling back to
+// PowerShell on Windows and bash elsewhere
+func defaultShell() string {
+	switch filepath.Base(os.Getenv("SHELL")) {
+	case shellZsh:
+		return shellZsh
+	case shellFish:
+		return shellFish
+	}
+	if runtime.GOOS == "windows" {
+		return shellPowerShell
+	}
+	return shellBash
+}
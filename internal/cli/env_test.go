@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/vanducng/cflip/internal/config"
+)
+
+func TestFormatEnvScript(t *testing.T) {
+	env := map[string]string{
+		config.EnvAuthToken: "tok'en",
+		config.EnvBaseURL:   "https://api.z.ai/api/anthropic",
+	}
+	owned := []string{config.EnvAuthToken, config.EnvAPIKey, config.EnvBaseURL}
+
+	tests := map[string]string{
+		shellBash: `unset ANTHROPIC_API_KEY
+export ANTHROPIC_AUTH_TOKEN='tok'\''en'
+export ANTHROPIC_BASE_URL='https://api.z.ai/api/anthropic'
+`,
+		shellFish: `set -e ANTHROPIC_API_KEY
+set -gx ANTHROPIC_AUTH_TOKEN 'tok\'en'
+set -gx ANTHROPIC_BASE_URL 'https://api.z.ai/api/anthropic'
+`,
+		shellPowerShell: `Remove-Item Env:ANTHROPIC_API_KEY -ErrorAction SilentlyContinue
+$env:ANTHROPIC_AUTH_TOKEN = 'tok''en'
+$env:ANTHROPIC_BASE_URL = 'https://api.z.ai/api/anthropic'
+`,
+	}
+	for shell, want := range tests {
+		if got := formatEnvScript(shell, env, owned, true); got != want {
+			t.Errorf("%s:\n--- got ---\n%s--- want ---\n%s", shell, got, want)
+		}
+	}
+
+	hidden := formatEnvScript(shellBash, env, owned, false)
+	if strings.Contains(hidden, "tok") || !strings.Contains(hidden, "# ANTHROPIC_AUTH_TOKEN hidden") {
+		t.Errorf("Expected the token to be hidden, got:\n%s", hidden)
+	}
+	if !strings.Contains(hidden, "export ANTHROPIC_BASE_URL=") {
+		t.Errorf("Expected non-secret values to be printed, got:\n%s", hidden)
+	}
+}
+
+func TestEnvCommand(t *testing.T) {
+	setupTestHome(t)
+	resetFlags(t, envCmd, "shell", "unset")
+	_ = envCmd.Flags().Set("shell", shellBash)
+
+	// Output to a pipe is not a terminal, so the token is included
+	out := captureStdout(t, func() error { return runEnv(envCmd, []string{glmProvider}) })
+	for _, want := range []string{
+		"export ANTHROPIC_AUTH_TOKEN='glm-test-token'",
+		"export ANTHROPIC_BASE_URL='https://api.z.ai/api/anthropic'",
+		"unset ANTHROPIC_API_KEY",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in:\n%s", want, out)
+		}
+	}
+
+	_ = envCmd.Flags().Set("unset", "true")
+	out = captureStdout(t, func() error { return runEnv(envCmd, nil) })
+	if strings.Contains(out, "export") || !strings.Contains(out, "unset ANTHROPIC_AUTH_TOKEN") {
+		t.Errorf("Expected only unset commands, got:\n%s", out)
+	}
+
+	_ = envCmd.Flags().Set("unset", "false")
+	_ = envCmd.Flags().Set("shell", "tcsh")
+	if err := runEnv(envCmd, nil); err == nil {
+		t.Error("Expected an unsupported shell to fail")
+	}
+}
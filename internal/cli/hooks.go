@@ -0,0 +1,45 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"time"
+)
+
+// hookTimeout bounds how long a switch hook may run
+const hookTimeout = 2 * time.Minute
+
+// runSwitchHook runs a hook command through the shell with the old and new
+// provider names in CFLIP_OLD_PROVIDER and CFLIP_NEW_PROVIDER
+func runSwitchHook(ctx context.Context, command, oldProvider, newProvider string) error {
+	if command == "" {
+		return nil
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, hookTimeout)
+	defer cancel()
+
+	var hook *exec.Cmd
+	if runtime.GOOS == "windows" {
+		hook = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		hook = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	hook.Env = append(os.Environ(),
+		"CFLIP_OLD_PROVIDER="+oldProvider,
+		"CFLIP_NEW_PROVIDER="+newProvider,
+	)
+	hook.Stdout = os.Stdout
+	hook.Stderr = os.Stderr
+
+	if err := hook.Run(); err != nil {
+		return fmt.Errorf("hook '%s' failed: %w", command, err)
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/vanducng/cflip/internal/config"
+)
+
+// customProvider is the name given to imported providers with an unrecognized base URL
+const customProvider = "custom"
+
+// importCmd groups commands that import configuration from other tools
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import configuration from other sources",
+}
+
+// importClaudeSettingsCmd creates a provider from the env of the Claude settings file
+var importClaudeSettingsCmd = &cobra.Command{
+	Use:   "claude-settings",
+	Short: "Create a provider from the existing Claude settings",
+	Long: `Read the token, base URL and model env vars from ~/.claude/settings.json,
+store them as a provider in the cflip config file and make it active. The
+provider is detected from the base URL; an unrecognized URL is stored as
+the custom provider unless --name is given.
+
+The Claude settings file itself is not changed.`,
+	Args: cobra.NoArgs,
+	RunE: runImportClaudeSettings,
+}
+
+func init() {
+	importClaudeSettingsCmd.Flags().String("name", "", "Provider name to import into (default: detected from the base URL)")
+	importClaudeSettingsCmd.Flags().String("settings-path", "", "Claude settings file to read (default ~/.claude/settings.json)")
+
+	importCmd.AddCommand(importClaudeSettingsCmd)
+}
+
+// NewImportCmd exports the import command
+func NewImportCmd() *cobra.Command {
+	return importCmd
+}
+
+func runImportClaudeSettings(cmd *cobra.Command, args []string) error {
+	quiet, _ := cmd.Flags().GetBool("quiet")
+	name, _ := cmd.Flags().GetString("name")
+	settingsPath := resolveSettingsPath(cmd)
+
+	if _, err := os.Stat(settingsPath); err != nil {
+		return fmt.Errorf("failed to read Claude settings: %w", err)
+	}
+	settings, err := LoadSettings(settingsPath)
+	if err != nil {
+		return fmt.Errorf("failed to load Claude settings: %w", err)
+	}
+
+	if name == "" {
+		name = detectCurrentProvider(settings)
+		if name == "external" {
+			name = customProvider
+		}
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	provider := cfg.Providers[name]
+	imported, skipped := importSettingsEnv(&provider, settings.Env, cfg.IsExternal(name))
+	if len(imported) == 0 {
+		return fmt.Errorf("no provider settings found in %s", settingsPath)
+	}
+
+	cfg.SetProviderConfig(name, provider)
+	if err := cfg.ValidateProvider(name); err != nil {
+		return err
+	}
+	if err := cfg.SetActiveProvider(name); err != nil {
+		return err
+	}
+
+	if err := config.SaveConfig(cfg); err != nil {
+		return fmt.Errorf("failed to save configuration: %w", err)
+	}
+
+	if !quiet {
+		fmt.Printf("%s Imported %s from %s\n", checkMark(), name, settingsPath)
+		for _, key := range imported {
+			fmt.Printf("  imported: %s\n", key)
+		}
+		for _, key := range skipped {
+			fmt.Printf("  skipped:  %s\n", key)
+		}
+	}
+	return nil
+}
+
+// importSettingsEnv copies the token, base URL and model mappings found in a
+// Claude settings env into provider. It returns the env keys it used and
+// the ones it left alone, both sorted.
+func importSettingsEnv(provider *config.ProviderConfig, env map[string]interface{}, external bool) (imported, skipped []string) {
+	categories := make(map[string]string, len(config.ModelCategories))
+	for _, category := range config.ModelCategories {
+		key, _ := config.CategoryEnvKey(category)
+		categories[key] = category
+	}
+
+	// A bearer token wins over an API key when both are set
+	_, hasAuthToken := env[config.EnvAuthToken]
+
+	for key, raw := range env {
+		value, ok := raw.(string)
+		if !ok || strings.TrimSpace(value) == "" {
+			skipped = append(skipped, key)
+			continue
+		}
+
+		switch category, isModel := categories[key]; {
+		case key == config.EnvAuthToken:
+			provider.Token = value
+			provider.AuthHeader = ""
+		case key == config.EnvAPIKey && !hasAuthToken:
+			provider.Token = value
+			if external {
+				provider.AuthHeader = config.AuthHeaderAPIKey
+			}
+		case key == config.EnvBaseURL && external:
+			provider.BaseURL = value
+		case isModel && external:
+			if provider.ModelMap == nil {
+				provider.ModelMap = make(map[string]string)
+			}
+			provider.ModelMap[category] = value
+		default:
+			skipped = append(skipped, key)
+			continue
+		}
+		imported = append(imported, key)
+	}
+
+	if !external && provider.Token != "" {
+		provider.AuthMode = config.AuthModeAPI
+	}
+
+	sort.Strings(imported)
+	sort.Strings(skipped)
+	return imported, skipped
+}
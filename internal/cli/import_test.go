@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/vanducng/cflip/internal/config"
+)
+
+func TestImportClaudeSettings(t *testing.T) {
+	setupTestHome(t)
+	resetFlags(t, importClaudeSettingsCmd, "name")
+
+	settingsPath := defaultSettingsPath()
+	writeTestSettings(t, settingsPath, map[string]interface{}{
+		config.EnvAuthToken:   "zai-token",
+		config.EnvBaseURL:     "https://api.z.ai/api/anthropic",
+		config.EnvSonnetModel: "glm-4.6",
+		"DISABLE_TELEMETRY":   "1",
+	})
+	before, err := os.ReadFile(settingsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := captureStdout(t, func() error {
+		return runImportClaudeSettings(importClaudeSettingsCmd, nil)
+	})
+	if !strings.Contains(out, "skipped:  DISABLE_TELEMETRY") {
+		t.Errorf("Expected unrelated env vars to be reported as skipped:\n%s", out)
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Provider != glmProvider {
+		t.Errorf("Expected glm to be active, got %s", cfg.Provider)
+	}
+	glm := cfg.Providers[glmProvider]
+	if glm.Token != "zai-token" || glm.ModelMap["sonnet"] != "glm-4.6" {
+		t.Errorf("Unexpected imported provider: %+v", glm)
+	}
+
+	after, err := os.ReadFile(settingsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(before, after) {
+		t.Error("The Claude settings file must not be modified")
+	}
+}
+
+func TestImportClaudeSettingsUnknownURL(t *testing.T) {
+	setupTestHome(t)
+	resetFlags(t, importClaudeSettingsCmd, "name")
+
+	writeTestSettings(t, defaultSettingsPath(), map[string]interface{}{
+		config.EnvAPIKey:  "proxy-key",
+		config.EnvBaseURL: "https://llm.internal.example.com",
+	})
+
+	if err := runImportClaudeSettings(importClaudeSettingsCmd, nil); err != nil {
+		t.Fatalf("import failed: %v", err)
+	}
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	custom, exists := cfg.Providers[customProvider]
+	if !exists || cfg.Provider != customProvider {
+		t.Fatalf("Expected an active custom provider, got %s", cfg.Provider)
+	}
+	if custom.AuthHeader != config.AuthHeaderAPIKey || custom.Token != "proxy-key" {
+		t.Errorf("Unexpected imported provider: %+v", custom)
+	}
+
+	_ = importClaudeSettingsCmd.Flags().Set("name", "corp")
+	if err := runImportClaudeSettings(importClaudeSettingsCmd, nil); err != nil {
+		t.Fatalf("import --name failed: %v", err)
+	}
+	cfg, err = config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Provider != "corp" || cfg.Providers["corp"].BaseURL != "https://llm.internal.example.com" {
+		t.Errorf("Expected the settings to be imported as corp, got %s", cfg.Provider)
+	}
+}
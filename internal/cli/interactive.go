@@ -3,12 +3,15 @@ package cli
 import (
 	"fmt"
 	"io"
+	"os"
 	"sort"
 
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
 	"github.com/vanducng/cflip/internal/config"
+	"golang.org/x/term"
 )
 
 var (
@@ -55,7 +58,7 @@ type item struct {
 
 func (i item) Title() string       { return i.title }
 func (i item) Description() string { return i.desc }
-func (i item) FilterValue() string { return i.title }
+func (i item) FilterValue() string { return i.providerName + " " + i.title }
 
 // model represents the interactive menu
 type model struct {
@@ -94,7 +97,7 @@ func initialModel(cfg *config.Config) model {
 	// Convert to items
 	var items []item
 	for _, name := range providerNames {
-		provider := cfg.Providers[name]
+		provider, _ := cfg.ResolveProvider(name)
 		displayName, statusText := getProviderDisplayInfo(name, provider)
 
 		title := displayName
@@ -128,7 +131,7 @@ func initialModel(cfg *config.Config) model {
 	l := list.New(listItems, compactDelegate{}, defaultWidth, listHeight)
 	l.Title = titleStyle.Render("Select Provider")
 	l.SetShowStatusBar(false)
-	l.SetFilteringEnabled(false)
+	l.SetFilteringEnabled(true)
 	l.SetShowHelp(false)
 	l.DisableQuitKeybindings()
 
@@ -155,6 +158,11 @@ func (m model) Init() tea.Cmd {
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		// While typing a filter, keys go to the filter input; enter applies it
+		if m.list.SettingFilter() && msg.String() != "ctrl+c" {
+			break
+		}
+
 		switch keypress := msg.String(); keypress {
 		case "q", "ctrl+c":
 			m.quitting = true
@@ -165,7 +173,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			selectedItem := m.list.SelectedItem()
 			if i, ok := selectedItem.(item); ok {
 				m.selected = i.providerName
-				m.selectedIdx = m.list.Index()
+				m.selectedIdx = m.list.GlobalIndex()
 				m.quitting = true
 				return m, tea.Quit
 			}
@@ -198,7 +206,11 @@ var docStyle = lipgloss.NewStyle().
 func RunInteractiveSelection(cfg *config.Config) (string, error) {
 	// Check if we're in a terminal
 	if !isTerminal() {
-		return "", fmt.Errorf("interactive mode requires a terminal")
+		return "", fmt.Errorf("interactive mode requires a terminal; pass a provider name instead: cflip switch <provider>")
+	}
+
+	if !colorEnabled() {
+		lipgloss.SetColorProfile(termenv.Ascii)
 	}
 
 	p := tea.NewProgram(initialModel(cfg))
@@ -215,9 +227,13 @@ func RunInteractiveSelection(cfg *config.Config) (string, error) {
 	return "", fmt.Errorf("no provider selected")
 }
 
-// isTerminal checks if we're running in a terminal
+// isTerminal reports whether both stdin and stdout are terminals, which the
+// interactive selection needs to read keys and draw the menu
 func isTerminal() bool {
-	// Simple check - in a real implementation, you might want to use
-	// something more sophisticated
-	return true
+	return isTerminalFile(os.Stdin) && isTerminalFile(os.Stdout)
+}
+
+// isTerminalFile reports whether f is a terminal
+func isTerminalFile(f *os.File) bool {
+	return f != nil && term.IsTerminal(int(f.Fd()))
 }
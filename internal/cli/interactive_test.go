@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/vanducng/cflip/internal/config"
+)
+
+func TestIsTerminalFalseForPipesAndFiles(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	file, err := os.Create(filepath.Join(t.TempDir(), "out"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	for _, f := range []*os.File{r, w, file, nil} {
+		if isTerminalFile(f) {
+			t.Errorf("Expected %v not to be a terminal", f)
+		}
+	}
+
+	origIn, origOut := os.Stdin, os.Stdout
+	os.Stdin, os.Stdout = r, file
+	got := isTerminal()
+	os.Stdin, os.Stdout = origIn, origOut
+	if got {
+		t.Error("Expected isTerminal to be false with piped stdin and file stdout")
+	}
+}
+
+func TestSwitchWithoutTerminalNeedsProvider(t *testing.T) {
+	setupTestHome(t)
+	withEmptyStdin(t)
+
+	err := runSwitch(switchCmd, nil)
+	if err == nil {
+		t.Fatal("Expected switch without a provider to fail outside a terminal")
+	}
+	if !strings.Contains(err.Error(), "cflip switch <provider>") {
+		t.Errorf("Expected the error to suggest passing a provider, got: %v", err)
+	}
+}
+
+// selectorFixtureConfig returns a config with glm active and a custom provider
+func selectorFixtureConfig() *config.Config {
+	cfg := config.NewConfig()
+	cfg.SetProviderConfig(glmProvider, config.ProviderConfig{Token: "glm-token", BaseURL: "https://api.z.ai/api/anthropic"})
+	cfg.SetProviderConfig("my-proxy", config.ProviderConfig{BaseURL: "http://localhost:4000"})
+	cfg.Provider = glmProvider
+	return cfg
+}
+
+func TestInitialModelListsProviders(t *testing.T) {
+	m := initialModel(selectorFixtureConfig())
+
+	var names []string
+	for _, listItem := range m.list.Items() {
+		names = append(names, listItem.(item).providerName)
+	}
+	want := []string{anthropicProvider, claudeCodeProvider, glmProvider, "my-proxy"}
+	if strings.Join(names, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected items %v, got %v", want, names)
+	}
+
+	if !m.list.FilteringEnabled() {
+		t.Error("Expected filtering to be enabled")
+	}
+	if selected := m.list.SelectedItem().(item).providerName; selected != glmProvider {
+		t.Errorf("Expected the current provider to be preselected, got %s", selected)
+	}
+}
+
+func TestInteractiveFilterSelectsMatch(t *testing.T) {
+	var m tea.Model = initialModel(selectorFixtureConfig())
+
+	// q while typing a filter is text, not quit
+	for _, r := range "/q" {
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	if m.(model).quitting {
+		t.Fatal("Expected q to go to the filter input")
+	}
+
+	filtered := m.(model)
+	filtered.list.SetFilterText("proxy")
+	m, _ = filtered.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	if selected := m.(model).selected; selected != "my-proxy" {
+		t.Errorf("Expected enter on the filtered result to select my-proxy, got %q", selected)
+	}
+}
@@ -1,8 +1,13 @@
 package cli
 
 import (
+	"encoding/json"
 	"fmt"
+	"net/url"
+	"os"
 	"sort"
+	"strings"
+	"text/tabwriter"
 
 	"github.com/spf13/cobra"
 	"github.com/vanducng/cflip/internal/config"
@@ -20,6 +25,8 @@ Shows provider names, plan types, and configuration status.`,
 
 func init() {
 	listCmd.Flags().BoolP("json", "j", false, "Output in JSON format")
+	listCmd.Flags().BoolP("wide", "w", false, "Show auth, base URL and model details")
+	listCmd.Flags().BoolP("all", "a", false, "Show every provider detail: auth method, key source, full base URL and aliases")
 }
 
 // NewListCmd exports the list command
@@ -29,6 +36,8 @@ func NewListCmd() *cobra.Command {
 
 func runList(cmd *cobra.Command, args []string) error {
 	jsonOutput, _ := cmd.Flags().GetBool("json")
+	wideOutput, _ := cmd.Flags().GetBool("wide")
+	allOutput, _ := cmd.Flags().GetBool("all")
 
 	// Load configuration
 	cfg, err := config.LoadConfig()
@@ -40,17 +49,21 @@ func runList(cmd *cobra.Command, args []string) error {
 		return outputProvidersJSON(cfg)
 	}
 
+	if allOutput {
+		return outputProvidersAll(cfg)
+	}
+
+	if wideOutput {
+		return outputProvidersWide(cfg)
+	}
+
 	return outputProvidersText(cfg)
 }
 
-func outputProvidersText(cfg *config.Config) error {
-	fmt.Println("Providers:")
-	fmt.Println()
-
-	// Always include anthropic as first option
+// sortedProviderNames returns anthropic first followed by the configured external providers in sorted order
+func sortedProviderNames(cfg *config.Config) []string {
 	providerNames := []string{anthropicProvider}
 
-	// Add configured external providers in sorted order
 	var externalProviders []string
 	for name := range cfg.Providers {
 		if name != anthropicProvider {
@@ -58,7 +71,120 @@ func outputProvidersText(cfg *config.Config) error {
 		}
 	}
 	sort.Strings(externalProviders)
-	providerNames = append(providerNames, externalProviders...)
+
+	return append(providerNames, externalProviders...)
+}
+
+// authSummary returns the auth column for a provider
+func authSummary(providerName string, provider config.ProviderConfig) string {
+	if (providerName == anthropicProvider && !provider.UsesAPIKey()) || provider.AuthMode == config.AuthModeSubscription {
+		return statusSubscription
+	}
+	if provider.Token != "" {
+		return "key ✓"
+	}
+	return "key ✗"
+}
+
+// baseURLHost returns the host part of a provider base URL, or "-" when unset
+func baseURLHost(provider config.ProviderConfig) string {
+	if provider.BaseURL == "" {
+		return "-"
+	}
+	u, err := url.Parse(provider.BaseURL)
+	if err != nil || u.Host == "" {
+		return provider.BaseURL
+	}
+	return u.Host
+}
+
+// valueOrDash returns "-" for empty values in table output
+func valueOrDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+func outputProvidersWide(cfg *config.Config) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "#\tNAME\tDISPLAY NAME\tAUTH\tBASE URL\tMODELS\tSONNET\tCURRENT")
+
+	for i, name := range sortedProviderNames(cfg) {
+		provider, _ := cfg.ResolveProvider(name)
+		displayName, _ := getProviderDisplayInfo(name, provider)
+
+		current := "-"
+		if cfg.Provider == name {
+			current = "*"
+		}
+
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%d\t%s\t%s\n",
+			i+1, name, displayName, authSummary(name, provider), baseURLHost(provider),
+			len(provider.ModelMap), valueOrDash(provider.ModelMap["sonnet"]), current)
+	}
+
+	return w.Flush()
+}
+
+func outputProvidersAll(cfg *config.Config) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tDISPLAY NAME\tAUTH\tKEY\tBASE URL\tMODELS\tALIASES\tCURRENT")
+
+	for _, name := range sortedProviderNames(cfg) {
+		provider, fromEnv := cfg.ResolveProvider(name)
+		displayName, _ := getProviderDisplayInfo(name, provider)
+
+		current := "-"
+		if cfg.Provider == name {
+			current = "*"
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%d\t%s\t%s\n",
+			name, displayName, authMethod(name, provider), keySource(name, provider, fromEnv),
+			valueOrDash(provider.BaseURL), len(provider.ModelMap),
+			valueOrDash(strings.Join(provider.Aliases, ",")), current)
+	}
+
+	return w.Flush()
+}
+
+// authMethod returns how a provider authenticates: the anthropic auth mode,
+// or the header an external provider's key is sent in
+func authMethod(providerName string, provider config.ProviderConfig) string {
+	if providerName == anthropicProvider {
+		if provider.UsesAPIKey() {
+			return config.AuthModeAPI
+		}
+		return config.AuthModeSubscription
+	}
+	if provider.AuthMode == config.AuthModeSubscription {
+		return config.AuthModeSubscription
+	}
+	if provider.TokenEnvKey() == config.EnvAPIKey {
+		return config.AuthHeaderAPIKey
+	}
+	return "bearer"
+}
+
+// keySource returns where a provider's API key comes from
+func keySource(providerName string, provider config.ProviderConfig, fromEnv bool) string {
+	switch {
+	case fromEnv:
+		return config.APIKeyEnvVar(providerName)
+	case provider.Token != "":
+		return "config"
+	default:
+		return "-"
+	}
+}
+
+func outputProvidersText(cfg *config.Config) error {
+	fmt.Println("Providers:")
+	fmt.Println()
+
+	// Always include anthropic as first option, then external providers in sorted order
+	providerNames := sortedProviderNames(cfg)
 
 	// Find current provider index
 	var currentIndex = -1
@@ -100,50 +226,57 @@ func outputProvidersText(cfg *config.Config) error {
 	return nil
 }
 
-func outputProvidersJSON(cfg *config.Config) error {
-	// Always include anthropic as first option
-	providerNames := []string{anthropicProvider}
+// listOutput is the JSON document printed by list --json
+type listOutput struct {
+	Current   string               `json:"current"`
+	Providers []listProviderOutput `json:"providers"`
+}
 
-	// Add configured external providers in sorted order
-	var externalProviders []string
-	for name := range cfg.Providers {
-		if name != anthropicProvider {
-			externalProviders = append(externalProviders, name)
-		}
-	}
-	sort.Strings(externalProviders)
-	providerNames = append(providerNames, externalProviders...)
+// listProviderOutput describes one provider in list --json output
+type listProviderOutput struct {
+	Index       int               `json:"index"`
+	Name        string            `json:"name"`
+	DisplayName string            `json:"displayName"`
+	Status      string            `json:"status"`
+	Auth        string            `json:"auth"`
+	HasAPIKey   bool              `json:"hasAPIKey"`
+	BaseURLHost string            `json:"baseURLHost"`
+	Models      int               `json:"models"`
+	SonnetModel string            `json:"sonnetModel"`
+	ModelMap    map[string]string `json:"modelMap,omitempty"`
+	IsCurrent   bool              `json:"isCurrent"`
+}
 
-	fmt.Println("{")
-	fmt.Printf(`  "current": "%s",`+"\n", cfg.Provider)
-	fmt.Println(`  "providers": [`)
+func outputProvidersJSON(cfg *config.Config) error {
+	output := listOutput{
+		Current:   cfg.Provider,
+		Providers: []listProviderOutput{},
+	}
 
-	for i, name := range providerNames {
-		provider := cfg.Providers[name]
+	for i, name := range sortedProviderNames(cfg) {
+		provider, _ := cfg.ResolveProvider(name)
 		displayName, statusText := getProviderDisplayInfo(name, provider)
 
-		fmt.Printf("    {")
-		fmt.Printf(`"index": %d, `, i+1)
-		fmt.Printf(`"name": "%s", `, name)
-		fmt.Printf(`"displayName": "%s", `, displayName)
-		if statusText == "API" {
-			fmt.Printf(`"status": "%s", `, statusText)
-		} else {
-			fmt.Printf(`"status": "OAuth", `)
-		}
-		fmt.Printf(`"isCurrent": %t`, cfg.Provider == name)
-
-		fmt.Printf("}")
-
-		if i < len(providerNames)-1 {
-			fmt.Println(",")
-		} else {
-			fmt.Println()
-		}
+		output.Providers = append(output.Providers, listProviderOutput{
+			Index:       i + 1,
+			Name:        name,
+			DisplayName: displayName,
+			Status:      statusText,
+			Auth:        authSummary(name, provider),
+			HasAPIKey:   provider.Token != "",
+			BaseURLHost: baseURLHost(provider),
+			Models:      len(provider.ModelMap),
+			SonnetModel: provider.ModelMap["sonnet"],
+			ModelMap:    provider.ModelMap,
+			IsCurrent:   cfg.Provider == name,
+		})
 	}
 
-	fmt.Println("  ]")
-	fmt.Println("}")
+	data, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal providers: %w", err)
+	}
 
+	fmt.Println(string(data))
 	return nil
 }
@@ -0,0 +1,139 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/vanducng/cflip/internal/config"
+)
+
+var updateGolden = flag.Bool("update", false, "update golden files")
+
+// captureStdout returns everything fn writes to stdout
+func captureStdout(t *testing.T, fn func() error) string {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	orig := os.Stdout
+	os.Stdout = w
+	runErr := fn()
+	w.Close()
+	os.Stdout = orig
+
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runErr != nil {
+		t.Fatalf("command failed: %v", runErr)
+	}
+	return string(out)
+}
+
+// assertGolden compares output against testdata/<name>.golden
+func assertGolden(t *testing.T, name, got string) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name+".golden")
+	if *updateGolden {
+		if err := os.WriteFile(path, []byte(got), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != string(want) {
+		t.Errorf("Output mismatch for %s\n--- got ---\n%s\n--- want ---\n%s", name, got, want)
+	}
+}
+
+// listFixtureConfig returns a config with anthropic, glm and a custom provider
+func listFixtureConfig() *config.Config {
+	cfg := config.NewConfig()
+	cfg.Provider = glmProvider
+	cfg.SetProviderConfig(anthropicProvider, config.ProviderConfig{
+		Token:    "sk-ant-test",
+		AuthMode: config.AuthModeSubscription,
+	})
+	cfg.SetProviderConfig(glmProvider, config.ProviderConfig{
+		Token:   "glm-token",
+		BaseURL: "https://api.z.ai/api/anthropic",
+		Aliases: []string{"zai"},
+		ModelMap: map[string]string{
+			"haiku":  "glm-4.5-air",
+			"sonnet": "glm-4.6",
+		},
+	})
+	cfg.SetProviderConfig("my-proxy", config.ProviderConfig{
+		BaseURL: "http://localhost:4000",
+	})
+	return cfg
+}
+
+func TestListOutputs(t *testing.T) {
+	cfg := listFixtureConfig()
+
+	tests := []struct {
+		name string
+		fn   func(*config.Config) error
+	}{
+		{"list_narrow", outputProvidersText},
+		{"list_wide", outputProvidersWide},
+		{"list_all", outputProvidersAll},
+		{"list_json", outputProvidersJSON},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := captureStdout(t, func() error { return tt.fn(cfg) })
+			assertGolden(t, tt.name, out)
+		})
+	}
+}
+
+func TestListJSONRoundTrip(t *testing.T) {
+	cfg := listFixtureConfig()
+	cfg.SetProviderConfig("quoted", config.ProviderConfig{
+		DisplayName: `My "quoted" \ proxy`,
+		BaseURL:     "https://proxy.example.com",
+		ModelMap:    map[string]string{"opus": "big-model"},
+	})
+
+	out := captureStdout(t, func() error { return outputProvidersJSON(cfg) })
+
+	var parsed listOutput
+	if err := json.Unmarshal([]byte(out), &parsed); err != nil {
+		t.Fatalf("list --json produced invalid JSON: %v\n%s", err, out)
+	}
+
+	if parsed.Current != glmProvider {
+		t.Errorf("Expected current provider glm, got %s", parsed.Current)
+	}
+
+	var found bool
+	for _, p := range parsed.Providers {
+		if p.Name == "quoted" {
+			found = true
+			if p.DisplayName != `My "quoted" \ proxy` {
+				t.Errorf("Display name not preserved: %s", p.DisplayName)
+			}
+			if p.ModelMap["opus"] != "big-model" {
+				t.Errorf("Model map not included: %v", p.ModelMap)
+			}
+		}
+	}
+	if !found {
+		t.Error("Provider with special characters missing from output")
+	}
+}
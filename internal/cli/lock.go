@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/vanducng/cflip/internal/config"
+)
+
+// readOnlyCommands never write the cflip config or Claude settings, so they
+// run without the config lock and are not blocked by a running switch. test
+// is listed because its network requests can take long; it takes the lock
+// itself around saving the validation times.
+var readOnlyCommands = map[string]bool{
+	"cflip status":                       true,
+	"cflip list":                         true,
+	"cflip current":                      true,
+	"cflip validate":                     true,
+	"cflip doctor":                       true,
+	"cflip env":                          true,
+	"cflip test":                         true,
+	"cflip completion":                   true,
+	"cflip help":                         true,
+	"cflip config show":                  true,
+	"cflip config export":                true,
+	"cflip snapshot list":                true,
+	"cflip snapshot diff":                true,
+	"cflip snapshot show":                true,
+	"cflip profile list":                 true,
+	"cflip " + cobra.ShellCompRequestCmd: true,
+	"cflip " + cobra.ShellCompNoDescRequestCmd: true,
+}
+
+// releaseConfigLock releases the lock taken by lockConfigForCommand
+var releaseConfigLock func()
+
+// lockConfigForCommand takes the config lock before a command that may
+// change the config or Claude settings, so concurrent invocations cannot
+// lose each other's updates
+func lockConfigForCommand(cmd *cobra.Command, args []string) error {
+	if readOnlyCommands[cmd.CommandPath()] {
+		return nil
+	}
+
+	unlock, err := config.LockConfig(config.LockTimeout)
+	if err != nil {
+		// A held lock is not a usage mistake
+		cmd.SilenceUsage = true
+		return err
+	}
+	releaseConfigLock = unlock
+	return nil
+}
+
+// unlockConfig releases the config lock if it is held
+func unlockConfig() {
+	if releaseConfigLock != nil {
+		releaseConfigLock()
+		releaseConfigLock = nil
+	}
+}
@@ -0,0 +1,45 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/vanducng/cflip/internal/config"
+)
+
+// migratePathsCmd moves an existing ~/.cflip to the XDG base directories
+var migratePathsCmd = &cobra.Command{
+	Use:   "migrate-paths",
+	Short: "Move ~/.cflip to the XDG base directories",
+	Long: `Move the config from ~/.cflip to $XDG_CONFIG_HOME/cflip (default
+~/.config/cflip) and its backup to $XDG_STATE_HOME/cflip (default
+~/.local/state/cflip). A MOVED file is left in ~/.cflip pointing at the new
+location.
+
+New installs on Linux use the XDG directories already; existing ~/.cflip
+configs keep working until they are migrated. macOS and Windows keep using
+~/.cflip.`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         runMigratePaths,
+}
+
+// NewMigratePathsCmd exports the migrate-paths command
+func NewMigratePathsCmd() *cobra.Command {
+	return migratePathsCmd
+}
+
+func runMigratePaths(cmd *cobra.Command, args []string) error {
+	quiet, _ := cmd.Flags().GetBool("quiet")
+
+	configPath, stateDir, err := config.MigrateLegacyDir()
+	if err != nil {
+		return err
+	}
+
+	if !quiet {
+		fmt.Printf("%s Moved config to %s\n", checkMark(), configPath)
+		fmt.Printf("Backups are now kept in %s\n", stateDir)
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/vanducng/cflip/internal/config"
+)
+
+// profileCmd groups commands that manage saved profiles
+var profileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "Save and load named provider setups",
+	Long: `A profile stores the active provider together with its auth mode and
+model mappings under a name, so a whole setup can be activated in one step.`,
+}
+
+// profileSaveCmd saves the active setup as a profile
+var profileSaveCmd = &cobra.Command{
+	Use:   "save <name>",
+	Short: "Save the active provider and model mappings as a profile",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runProfileSave,
+}
+
+// profileLoadCmd activates a profile
+var profileLoadCmd = &cobra.Command{
+	Use:               "load <name>",
+	Short:             "Activate a profile and regenerate Claude settings",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeProfileNames,
+	RunE:              runProfileLoad,
+}
+
+// profileListCmd lists saved profiles
+var profileListCmd = &cobra.Command{
+	Use:     "list",
+	Short:   "List saved profiles",
+	Aliases: []string{"ls"},
+	Args:    cobra.NoArgs,
+	RunE:    runProfileList,
+}
+
+// profileDeleteCmd removes a profile
+var profileDeleteCmd = &cobra.Command{
+	Use:               "delete <name>",
+	Short:             "Delete a saved profile",
+	Aliases:           []string{"rm"},
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeProfileNames,
+	RunE:              runProfileDelete,
+}
+
+func init() {
+	profileSaveCmd.Flags().Bool("force", false, "Overwrite an existing profile")
+	profileLoadCmd.Flags().String("settings-path", "", "Claude settings file to write (default ~/.claude/settings.json)")
+
+	profileCmd.AddCommand(profileSaveCmd)
+	profileCmd.AddCommand(profileLoadCmd)
+	profileCmd.AddCommand(profileListCmd)
+	profileCmd.AddCommand(profileDeleteCmd)
+}
+
+// NewProfileCmd exports the profile command
+func NewProfileCmd() *cobra.Command {
+	return profileCmd
+}
+
+func runProfileSave(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	quiet, _ := cmd.Flags().GetBool("quiet")
+	force, _ := cmd.Flags().GetBool("force")
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	if _, exists := cfg.Profiles[name]; exists && !force {
+		return fmt.Errorf("profile '%s' already exists (use --force to overwrite)", name)
+	}
+
+	if err := cfg.SaveProfile(name); err != nil {
+		return err
+	}
+
+	if err := config.SaveConfig(cfg); err != nil {
+		return fmt.Errorf("failed to save configuration: %w", err)
+	}
+
+	if !quiet {
+		fmt.Printf("%s Saved profile %s (%s)\n", checkMark(), name, cfg.Provider)
+	}
+	return nil
+}
+
+func runProfileLoad(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	verbose, _ := cmd.Flags().GetBool("verbose")
+	quiet, _ := cmd.Flags().GetBool("quiet")
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	oldProvider := cfg.Provider
+	if err := cfg.ApplyProfile(name); err != nil {
+		return err
+	}
+
+	if err := config.SaveConfig(cfg); err != nil {
+		return fmt.Errorf("failed to save configuration: %w", err)
+	}
+
+	if err := generateSwitchSettings(cfg, resolveSettingsPath(cmd), oldProvider, false, verbose, quiet); err != nil {
+		return fmt.Errorf("failed to generate Claude settings: %w", err)
+	}
+
+	if !quiet {
+		fmt.Printf("%s Loaded profile %s\n", checkMark(), name)
+		displaySwitchSuccess(cfg, cfg.Provider, verbose)
+	}
+	return nil
+}
+
+func runProfileList(cmd *cobra.Command, args []string) error {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	names := cfg.ProfileNames()
+	if len(names) == 0 {
+		fmt.Println("No profiles saved (use cflip profile save <name>)")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tPROVIDER\tAUTH\tMODELS")
+	for _, name := range names {
+		profile := cfg.Profiles[name]
+
+		var models []string
+		for _, category := range config.ModelCategories {
+			if model, ok := profile.ModelMap[category]; ok {
+				models = append(models, category+"="+model)
+			}
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
+			name, profile.Provider, valueOrDash(profile.AuthMode), valueOrDash(strings.Join(models, ",")))
+	}
+	return w.Flush()
+}
+
+func runProfileDelete(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	quiet, _ := cmd.Flags().GetBool("quiet")
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	if err := cfg.DeleteProfile(name); err != nil {
+		return err
+	}
+
+	if err := config.SaveConfig(cfg); err != nil {
+		return fmt.Errorf("failed to save configuration: %w", err)
+	}
+
+	if !quiet {
+		fmt.Printf("%s Deleted profile %s\n", checkMark(), name)
+	}
+	return nil
+}
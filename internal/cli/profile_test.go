@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/vanducng/cflip/internal/config"
+)
+
+func TestProfileSaveLoadRoundTrip(t *testing.T) {
+	setupTestHome(t)
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider := cfg.Providers[glmProvider]
+	provider.ModelMap = map[string]string{"haiku": "glm-4.5-air", "sonnet": "glm-4.6"}
+	cfg.SetProviderConfig(glmProvider, provider)
+	if err := cfg.SetActiveProvider(glmProvider); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.SaveConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runProfileSave(profileSaveCmd, []string{"cheap-glm"}); err != nil {
+		t.Fatalf("profile save failed: %v", err)
+	}
+	if err := runProfileSave(profileSaveCmd, []string{"cheap-glm"}); err == nil {
+		t.Error("Expected an error when saving over an existing profile without --force")
+	}
+
+	// Change the live setup, then load the profile back
+	cfg, err = config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider = cfg.Providers[glmProvider]
+	provider.ModelMap = map[string]string{"opus": "glm-other"}
+	cfg.SetProviderConfig(glmProvider, provider)
+	if err := cfg.SetActiveProvider(anthropicProvider); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.SaveConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runProfileLoad(profileLoadCmd, []string{"cheap-glm"}); err != nil {
+		t.Fatalf("profile load failed: %v", err)
+	}
+
+	cfg, err = config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Provider != glmProvider {
+		t.Errorf("Expected glm to be active, got %s", cfg.Provider)
+	}
+	modelMap := cfg.Providers[glmProvider].ModelMap
+	if len(modelMap) != 2 || modelMap["sonnet"] != "glm-4.6" {
+		t.Errorf("Expected the profile model mappings, got %v", modelMap)
+	}
+
+	settings, err := LoadSettings(defaultSettingsPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := settings.Env[config.EnvHaikuModel]; got != "glm-4.5-air" {
+		t.Errorf("Expected settings to be regenerated from the profile, got %v", got)
+	}
+	if _, ok := settings.Env[config.EnvOpusModel]; ok {
+		t.Error("Model mapping not in the profile should be cleared")
+	}
+}
+
+func TestProfileLoadMissing(t *testing.T) {
+	setupTestHome(t)
+
+	if err := runProfileLoad(profileLoadCmd, []string{"nope"}); err == nil {
+		t.Error("Expected an error for a profile that does not exist")
+	}
+	if err := runProfileDelete(profileDeleteCmd, []string{"nope"}); err == nil {
+		t.Error("Expected an error when deleting a profile that does not exist")
+	}
+}
@@ -0,0 +1,422 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/vanducng/cflip/internal/config"
+	"golang.org/x/term"
+)
+
+// providerCmd groups commands that manage providers
+var providerCmd = &cobra.Command{
+	Use:   "provider",
+	Short: "Manage providers",
+	Long: `Define and manage Anthropic-compatible providers such as LiteLLM,
+OpenRouter or corporate proxies.`,
+}
+
+// providerAddCmd defines a custom provider
+var providerAddCmd = &cobra.Command{
+	Use:   "add <name>",
+	Short: "Define a custom provider",
+	Long: `Define a custom Anthropic-compatible provider. Pass the settings as flags,
+or run without flags in a terminal to be asked for them:
+
+  cflip provider add litellm --base-url http://localhost:4000 \
+    --model haiku=claude-haiku --model sonnet=claude-sonnet
+
+In a terminal you are offered to enter the API key right away; otherwise
+it is asked for on the first switch or set with cflip config set-api-key.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runProviderAdd,
+}
+
+// providerRemoveCmd deletes a provider
+var providerRemoveCmd = &cobra.Command{
+	Use:     "remove <name>",
+	Aliases: []string{"rm"},
+	Short:   "Remove a provider",
+	Long: `Remove a provider, its API key, its model mappings and the profiles
+that use it. The active provider can only be removed together with
+--switch-to, which switches to another provider first, the same way cflip
+switch does, hooks and prompts included.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeProviderNames,
+	RunE:              runConfigRemoveProvider,
+}
+
+// providerRenameCmd gives a provider a new name
+var providerRenameCmd = &cobra.Command{
+	Use:   "rename <old> <new>",
+	Short: "Rename a provider",
+	Long: `Rename a provider. The active and previous provider and the profiles that
+use it are updated to the new name. Existing snapshots keep the old name.
+
+To keep switching by another name, list it in the provider's aliases:
+
+  [providers.glm-prod]
+  aliases = ["glm"]`,
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeProviderNames,
+	RunE:              runProviderRename,
+}
+
+// providerEditCmd changes settings of an existing provider
+var providerEditCmd = &cobra.Command{
+	Use:   "edit <name>",
+	Short: "Change settings of a provider",
+	Long: `Change the base URL, auth header, display name or extra env vars of a
+provider and print what changed. Claude settings are regenerated when the
+provider is active.
+
+  cflip provider edit litellm --base-url http://localhost:4001 \
+    --env HTTP_PROXY=http://proxy:3128 --unset env:NO_PROXY
+
+--timeout sets API_TIMEOUT_MS, the request timeout of Claude Code. The
+token, base URL and model env vars cflip writes itself, such as
+ANTHROPIC_AUTH_TOKEN, cannot be set with --env.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeProviderNames,
+	RunE:              runProviderEdit,
+}
+
+func init() {
+	providerAddCmd.Flags().String("display-name", "", "Name shown in lists and menus")
+	providerAddCmd.Flags().String("base-url", "", "Anthropic-compatible API base URL")
+	providerAddCmd.Flags().String("auth-header", "", "Header for the token: authorization or x-api-key")
+	providerAddCmd.Flags().StringArray("model", nil, "Model mapping as category=model (repeatable)")
+	providerAddCmd.Flags().Bool("force", false, "Overwrite an existing provider")
+
+	_ = providerAddCmd.RegisterFlagCompletionFunc("model", completeModelMappings)
+	_ = providerAddCmd.RegisterFlagCompletionFunc("auth-header",
+		fixedCompletion(config.AuthHeaderAuthorization, config.AuthHeaderAPIKey))
+
+	providerRemoveCmd.Flags().String("switch-to", "", "Provider to activate when removing the active one")
+	providerRemoveCmd.Flags().String("settings-path", "", "Claude settings file to write (default ~/.claude/settings.json)")
+	_ = providerRemoveCmd.RegisterFlagCompletionFunc("switch-to", completeProviderNames)
+
+	providerRenameCmd.Flags().String("settings-path", "", "Claude settings file to write (default ~/.claude/settings.json)")
+
+	providerEditCmd.Flags().String("display-name", "", "Name shown in lists and menus")
+	providerEditCmd.Flags().String("base-url", "", "Anthropic-compatible API base URL")
+	providerEditCmd.Flags().String("auth-header", "", "Header for the token: authorization or x-api-key")
+	providerEditCmd.Flags().Duration("timeout", 0, "Request timeout written as API_TIMEOUT_MS, e.g. 10m")
+	providerEditCmd.Flags().StringArray("env", nil, "Extra env var as KEY=VALUE (repeatable)")
+	providerEditCmd.Flags().StringArray("unset", nil, "Remove a setting, as env:KEY (repeatable)")
+	providerEditCmd.Flags().String("settings-path", "", "Claude settings file to write (default ~/.claude/settings.json)")
+	_ = providerEditCmd.RegisterFlagCompletionFunc("auth-header",
+		fixedCompletion(config.AuthHeaderAuthorization, config.AuthHeaderAPIKey))
+
+	providerCmd.AddCommand(providerAddCmd)
+	providerCmd.AddCommand(providerEditCmd)
+	providerCmd.AddCommand(providerRemoveCmd)
+	providerCmd.AddCommand(providerRenameCmd)
+}
+
+// NewProviderCmd exports the provider command
+func NewProviderCmd() *cobra.Command {
+	return providerCmd
+}
+
+func runProviderAdd(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	quiet, _ := cmd.Flags().GetBool("quiet")
+	force, _ := cmd.Flags().GetBool("force")
+	interactive := !providerFlagsGiven(cmd) && term.IsTerminal(int(os.Stdin.Fd()))
+
+	var provider config.ProviderConfig
+	var err error
+	if interactive {
+		reader := bufio.NewReader(os.Stdin)
+		if provider, err = promptProviderWizard(reader, name); err != nil {
+			return err
+		}
+		if provider.Token, err = promptOptionalAPIKey(reader, name); err != nil {
+			return err
+		}
+	} else if provider, err = providerFromFlags(cmd); err != nil {
+		return err
+	}
+
+	if err := addProvider(name, provider, force); err != nil {
+		return err
+	}
+
+	if !quiet {
+		fmt.Printf("%s Added provider %s\n", checkMark(), name)
+		if provider.Token == "" {
+			fmt.Printf("Set its API key with cflip config set-api-key %s, or on the first switch\n", name)
+		}
+	}
+	return nil
+}
+
+// providerFlagsGiven reports whether provider add got any of the flags that
+// describe the provider. Global flags such as --verbose and --force still
+// run the wizard.
+func providerFlagsGiven(cmd *cobra.Command) bool {
+	for _, flag := range []string{"display-name", "base-url", "auth-header", "model"} {
+		if cmd.Flags().Changed(flag) {
+			return true
+		}
+	}
+	return false
+}
+
+// timeoutEnvKey is the Claude Code env var holding the API request timeout
+const timeoutEnvKey = "API_TIMEOUT_MS"
+
+func runProviderEdit(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	quiet, _ := cmd.Flags().GetBool("quiet")
+	flags := cmd.Flags()
+
+	changed := false
+	for _, flag := range []string{"display-name", "base-url", "auth-header", "timeout", "env", "unset"} {
+		changed = changed || flags.Changed(flag)
+	}
+	if !changed {
+		return fmt.Errorf("nothing to change; see cflip provider edit --help")
+	}
+
+	displayName, _ := flags.GetString("display-name")
+	baseURL, _ := flags.GetString("base-url")
+	authHeader, _ := flags.GetString("auth-header")
+	timeout, _ := flags.GetDuration("timeout")
+	envPairs, _ := flags.GetStringArray("env")
+	unsets, _ := flags.GetStringArray("unset")
+
+	if flags.Changed("base-url") {
+		if err := config.ValidateBaseURL(baseURL); err != nil {
+			return err
+		}
+	}
+	if flags.Changed("timeout") && timeout <= 0 {
+		return fmt.Errorf("--timeout must be positive")
+	}
+
+	setEnv := make(map[string]string, len(envPairs))
+	for _, pair := range envPairs {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			return fmt.Errorf("invalid env var '%s' (expected KEY=VALUE)", pair)
+		}
+		setEnv[strings.TrimSpace(key)] = value
+	}
+	if flags.Changed("timeout") {
+		setEnv[timeoutEnvKey] = fmt.Sprintf("%d", timeout.Milliseconds())
+	}
+
+	var unsetEnv []string
+	for _, target := range unsets {
+		key, ok := strings.CutPrefix(target, "env:")
+		if !ok || key == "" {
+			return fmt.Errorf("invalid --unset value '%s' (expected env:KEY)", target)
+		}
+		unsetEnv = append(unsetEnv, key)
+	}
+
+	var changes []string
+	err := updateProvider(cmd, name, func(provider *config.ProviderConfig) {
+		before := *provider
+		before.EnvVars = copyEnvVars(provider.EnvVars)
+
+		if flags.Changed("display-name") {
+			provider.DisplayName = displayName
+		}
+		if flags.Changed("base-url") {
+			provider.BaseURL = baseURL
+		}
+		if flags.Changed("auth-header") {
+			provider.AuthHeader = strings.ToLower(authHeader)
+		}
+		for key, value := range setEnv {
+			if provider.EnvVars == nil {
+				provider.EnvVars = make(map[string]string)
+			}
+			provider.EnvVars[key] = value
+		}
+		for _, key := range unsetEnv {
+			delete(provider.EnvVars, key)
+		}
+
+		changes = describeProviderChanges(before, *provider)
+	}, fmt.Sprintf("Updated provider %s", name))
+	if err != nil {
+		return err
+	}
+
+	if !quiet {
+		if len(changes) == 0 {
+			fmt.Println("  No changes")
+		}
+		for _, change := range changes {
+			fmt.Printf("  %s\n", change)
+		}
+	}
+	return nil
+}
+
+// copyEnvVars returns a copy of a provider's env vars
+func copyEnvVars(env map[string]string) map[string]string {
+	out := make(map[string]string, len(env))
+	for key, value := range env {
+		out[key] = value
+	}
+	return out
+}
+
+// describeProviderChanges lists the settings that differ between two
+// versions of a provider as "field: old → new" lines
+func describeProviderChanges(before, after config.ProviderConfig) []string {
+	var changes []string
+	describe := func(field, oldValue, newValue string) {
+		if oldValue == newValue {
+			return
+		}
+		if oldValue == "" {
+			oldValue = "(unset)"
+		}
+		if newValue == "" {
+			newValue = "(unset)"
+		}
+		changes = append(changes, fmt.Sprintf("%s: %s → %s", field, oldValue, newValue))
+	}
+
+	describe("display_name", before.DisplayName, after.DisplayName)
+	describe("base_url", before.BaseURL, after.BaseURL)
+	describe("auth_header", before.AuthHeader, after.AuthHeader)
+
+	keys := make(map[string]bool)
+	for key := range before.EnvVars {
+		keys[key] = true
+	}
+	for key := range after.EnvVars {
+		keys[key] = true
+	}
+	sortedKeys := make([]string, 0, len(keys))
+	for key := range keys {
+		sortedKeys = append(sortedKeys, key)
+	}
+	sort.Strings(sortedKeys)
+	for _, key := range sortedKeys {
+		describe("env."+key, before.EnvVars[key], after.EnvVars[key])
+	}
+
+	return changes
+}
+
+func runProviderRename(cmd *cobra.Command, args []string) error {
+	oldName, newName := args[0], args[1]
+	verbose, _ := cmd.Flags().GetBool("verbose")
+	quiet, _ := cmd.Flags().GetBool("quiet")
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	if err := cfg.RenameProvider(oldName, newName); err != nil {
+		return err
+	}
+	if err := cfg.ValidateProvider(newName); err != nil {
+		return err
+	}
+
+	if err := config.SaveConfig(cfg); err != nil {
+		return fmt.Errorf("failed to save configuration: %w", err)
+	}
+
+	// The env API key variable is named after the provider, so the active
+	// provider's settings may change with its name
+	if cfg.Provider == newName {
+		if err := generateClaudeSettings(cfg, resolveSettingsPath(cmd), false, verbose, quiet); err != nil {
+			return fmt.Errorf("failed to generate Claude settings: %w", err)
+		}
+	}
+
+	if !quiet {
+		fmt.Printf("%s Renamed provider %s to %s\n", checkMark(), oldName, newName)
+	}
+	return nil
+}
+
+// providerFromFlags builds a provider config from the provider add flags
+func providerFromFlags(cmd *cobra.Command) (config.ProviderConfig, error) {
+	displayName, _ := cmd.Flags().GetString("display-name")
+	baseURL, _ := cmd.Flags().GetString("base-url")
+	authHeader, _ := cmd.Flags().GetString("auth-header")
+	models, _ := cmd.Flags().GetStringArray("model")
+
+	modelMap, err := parseModelMappings(strings.Join(models, ","))
+	if err != nil {
+		return config.ProviderConfig{}, err
+	}
+
+	return config.ProviderConfig{
+		DisplayName: displayName,
+		BaseURL:     baseURL,
+		AuthHeader:  strings.ToLower(authHeader),
+		ModelMap:    modelMap,
+	}, nil
+}
+
+// promptProviderWizard asks for the settings of a new provider
+func promptProviderWizard(reader *bufio.Reader, name string) (config.ProviderConfig, error) {
+	var provider config.ProviderConfig
+
+	provider.BaseURL = promptLine(reader, fmt.Sprintf("Base URL for %s: ", name))
+	if err := config.ValidateBaseURL(provider.BaseURL); err != nil {
+		return provider, err
+	}
+
+	provider.DisplayName = promptLine(reader, "Display name (optional): ")
+	provider.AuthHeader = strings.ToLower(promptLine(reader,
+		fmt.Sprintf("Auth header, %s or %s (optional): ", config.AuthHeaderAuthorization, config.AuthHeaderAPIKey)))
+
+	for _, category := range config.ModelCategories {
+		model := promptLine(reader, fmt.Sprintf("Model for %s category (optional): ", category))
+		if model == "" {
+			continue
+		}
+		if provider.ModelMap == nil {
+			provider.ModelMap = make(map[string]string)
+		}
+		provider.ModelMap[category] = model
+	}
+
+	return provider, nil
+}
+
+// promptOptionalAPIKey offers to enter an API key without echo
+func promptOptionalAPIKey(reader *bufio.Reader, name string) (string, error) {
+	answer := promptLine(reader, "Enter the API key now? (y/N): ")
+	if answer = strings.ToLower(answer); answer != "y" && answer != yesResponse {
+		return "", nil
+	}
+
+	key, err := readAPIKey(name, false, "")
+	if err != nil {
+		return "", err
+	}
+	if err := validateAPIKeyFormat(key); err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+// promptLine prints a prompt and returns the trimmed answer
+func promptLine(reader *bufio.Reader, prompt string) string {
+	fmt.Print(prompt)
+	input, err := reader.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return ""
+	}
+	return strings.TrimSpace(input)
+}
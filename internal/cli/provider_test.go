@@ -0,0 +1,336 @@
+package cli
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+
+	"github.com/vanducng/cflip/internal/config"
+)
+
+func TestProviderAddWizardIgnoresGlobalFlags(t *testing.T) {
+	registerCommands()
+	resetFlags(t, providerAddCmd, "verbose", "force", "base-url")
+
+	// Global flags are counted by NFlag but do not describe the provider
+	if err := providerAddCmd.ParseFlags([]string{"-v", "--force"}); err != nil {
+		t.Fatal(err)
+	}
+	if providerFlagsGiven(providerAddCmd) {
+		t.Error("Expected -v and --force to still run the wizard")
+	}
+
+	if err := providerAddCmd.ParseFlags([]string{"--base-url", "http://localhost:4000"}); err != nil {
+		t.Fatal(err)
+	}
+	if !providerFlagsGiven(providerAddCmd) {
+		t.Error("Expected --base-url to skip the wizard")
+	}
+}
+
+func TestProviderAdd(t *testing.T) {
+	setupTestHome(t)
+	resetFlags(t, providerAddCmd, "base-url", "auth-header", "display-name", "model", "force")
+
+	_ = providerAddCmd.Flags().Set("base-url", "http://localhost:4000")
+	_ = providerAddCmd.Flags().Set("auth-header", "X-API-Key")
+	_ = providerAddCmd.Flags().Set("display-name", "LiteLLM")
+	_ = providerAddCmd.Flags().Set("model", "haiku=claude-haiku")
+	_ = providerAddCmd.Flags().Set("model", "sonnet=claude-sonnet")
+
+	if err := runProviderAdd(providerAddCmd, []string{"litellm"}); err != nil {
+		t.Fatalf("provider add failed: %v", err)
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider, exists := cfg.Providers["litellm"]
+	if !exists {
+		t.Fatal("Provider should have been saved")
+	}
+	if provider.BaseURL != "http://localhost:4000" || provider.DisplayName != "LiteLLM" {
+		t.Errorf("Unexpected provider config: %+v", provider)
+	}
+	if provider.AuthHeader != config.AuthHeaderAPIKey {
+		t.Errorf("Expected auth header %s, got %s", config.AuthHeaderAPIKey, provider.AuthHeader)
+	}
+	if provider.ModelMap["haiku"] != "claude-haiku" || provider.ModelMap["sonnet"] != "claude-sonnet" {
+		t.Errorf("Unexpected model map: %v", provider.ModelMap)
+	}
+	if provider.Token != "" {
+		t.Error("No API key should be stored without the wizard")
+	}
+
+	if err := runProviderAdd(providerAddCmd, []string{"litellm"}); err == nil {
+		t.Error("Expected duplicate provider to be rejected")
+	}
+
+	_ = providerAddCmd.Flags().Set("force", "true")
+	_ = providerAddCmd.Flags().Set("display-name", "LiteLLM Proxy")
+	if err := runProviderAdd(providerAddCmd, []string{"litellm"}); err != nil {
+		t.Fatalf("provider add --force failed: %v", err)
+	}
+	cfg, err = config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.Providers["litellm"].DisplayName; got != "LiteLLM Proxy" {
+		t.Errorf("Expected --force to overwrite the provider, got %s", got)
+	}
+}
+
+func TestProviderAddValidation(t *testing.T) {
+	setupTestHome(t)
+	resetFlags(t, providerAddCmd, "base-url", "auth-header", "model")
+
+	_ = providerAddCmd.Flags().Set("base-url", "https://example.com")
+	_ = providerAddCmd.Flags().Set("auth-header", "bearer")
+	if err := runProviderAdd(providerAddCmd, []string{"bad-header"}); err == nil {
+		t.Error("Expected an unknown auth header to be rejected")
+	}
+
+	_ = providerAddCmd.Flags().Set("auth-header", "")
+	_ = providerAddCmd.Flags().Set("model", "sonnet")
+	if err := runProviderAdd(providerAddCmd, []string{"bad-model"}); err == nil {
+		t.Error("Expected a mapping without = to be rejected")
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"bad-model", "bad-header"} {
+		if _, exists := cfg.Providers[name]; exists {
+			t.Errorf("Invalid provider %s should not have been saved", name)
+		}
+	}
+}
+
+func TestPromptProviderWizard(t *testing.T) {
+	input := "https://openrouter.ai/api\nOpenRouter\nauthorization\nanthropic/claude-haiku\n\nanthropic/claude-opus\n"
+
+	provider, err := promptProviderWizard(bufio.NewReader(strings.NewReader(input)), "openrouter")
+	if err != nil {
+		t.Fatalf("wizard failed: %v", err)
+	}
+
+	if provider.BaseURL != "https://openrouter.ai/api" || provider.DisplayName != "OpenRouter" {
+		t.Errorf("Unexpected provider config: %+v", provider)
+	}
+	if provider.AuthHeader != config.AuthHeaderAuthorization {
+		t.Errorf("Expected auth header %s, got %s", config.AuthHeaderAuthorization, provider.AuthHeader)
+	}
+	if len(provider.ModelMap) != 2 || provider.ModelMap["opus"] != "anthropic/claude-opus" {
+		t.Errorf("Expected skipped categories to stay unmapped, got %v", provider.ModelMap)
+	}
+
+	if _, err := promptProviderWizard(bufio.NewReader(strings.NewReader("\n")), "openrouter"); err == nil {
+		t.Error("Expected an empty base URL to be rejected")
+	}
+}
+
+func TestProviderRemove(t *testing.T) {
+	setupTestHome(t)
+	resetFlags(t, providerRemoveCmd, "switch-to")
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.SetProviderConfig("litellm", config.ProviderConfig{Token: "litellm-token", BaseURL: "http://localhost:4000"})
+	if err := cfg.SetActiveProvider("litellm"); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.SaveProfile("local"); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.SetActiveProvider(glmProvider); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.SaveProfile("work"); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.SaveConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("nonexistent provider", func(t *testing.T) {
+		if err := runConfigRemoveProvider(providerRemoveCmd, []string{"missing"}); err == nil {
+			t.Error("Expected removing an unknown provider to fail")
+		}
+	})
+
+	t.Run("active provider is blocked", func(t *testing.T) {
+		if err := runConfigRemoveProvider(providerRemoveCmd, []string{glmProvider}); err == nil {
+			t.Error("Expected removing the active provider to fail")
+		}
+	})
+
+	t.Run("provider used by a profile", func(t *testing.T) {
+		if err := runConfigRemoveProvider(providerRemoveCmd, []string{"litellm"}); err != nil {
+			t.Fatalf("provider remove failed: %v", err)
+		}
+
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, exists := cfg.Providers["litellm"]; exists {
+			t.Error("Provider should have been removed")
+		}
+		if _, exists := cfg.Profiles["local"]; exists {
+			t.Error("Profiles using the provider should have been removed")
+		}
+		if _, exists := cfg.Profiles["work"]; !exists {
+			t.Error("Profiles of other providers must be kept")
+		}
+		if cfg.PreviousProvider == "litellm" {
+			t.Error("The previous provider must not point at a removed provider")
+		}
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("Config should stay valid after removal: %v", err)
+		}
+	})
+}
+
+func TestProviderRenameKeepsSwitchingByAlias(t *testing.T) {
+	setupTestHome(t)
+	withEmptyStdin(t)
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.SetProviderConfig(glmProvider, config.ProviderConfig{Token: "glm-token", BaseURL: "https://api.z.ai/api/anthropic"})
+	if err := config.SaveConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runProviderRename(providerRenameCmd, []string{glmProvider, "glm-prod"}); err != nil {
+		t.Fatalf("provider rename failed: %v", err)
+	}
+	if err := runProviderRename(providerRenameCmd, []string{"missing", "other"}); err == nil {
+		t.Error("Expected renaming an unknown provider to fail")
+	}
+
+	cfg, err = config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	prod := cfg.Providers["glm-prod"]
+	prod.Aliases = []string{glmProvider}
+	cfg.SetProviderConfig("glm-prod", prod)
+	if err := config.SaveConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runSwitch(switchCmd, []string{glmProvider}); err != nil {
+		t.Fatalf("switch by alias failed: %v", err)
+	}
+
+	cfg, err = config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Provider != "glm-prod" {
+		t.Errorf("Expected the alias to activate glm-prod, got %s", cfg.Provider)
+	}
+	if _, exists := cfg.Providers[glmProvider]; exists {
+		t.Error("Switching by alias must not create a provider under the alias name")
+	}
+}
+
+func TestProviderEdit(t *testing.T) {
+	setupTestHome(t)
+	resetFlags(t, providerEditCmd, "base-url", "display-name", "timeout", "env", "unset")
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.SetProviderConfig("litellm", config.ProviderConfig{
+		Token:   "litellm-token",
+		BaseURL: "http://localhost:4000",
+		EnvVars: map[string]string{"NO_PROXY": "localhost"},
+	})
+	if err := cfg.SetActiveProvider("litellm"); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.SaveConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runProviderEdit(providerEditCmd, []string{"litellm"}); err == nil {
+		t.Error("Expected edit without changes to fail")
+	}
+
+	_ = providerEditCmd.Flags().Set("base-url", "http://localhost:4001")
+	_ = providerEditCmd.Flags().Set("timeout", "10m")
+	_ = providerEditCmd.Flags().Set("env", "HTTP_PROXY=http://proxy:3128")
+	_ = providerEditCmd.Flags().Set("unset", "env:NO_PROXY")
+
+	out := captureStdout(t, func() error {
+		return runProviderEdit(providerEditCmd, []string{"litellm"})
+	})
+	for _, want := range []string{
+		"base_url: http://localhost:4000 → http://localhost:4001",
+		"env.API_TIMEOUT_MS: (unset) → 600000",
+		"env.NO_PROXY: localhost → (unset)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in summary:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "display_name") {
+		t.Errorf("Unchanged fields must not be listed:\n%s", out)
+	}
+
+	cfg, err = config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider := cfg.Providers["litellm"]
+	if provider.BaseURL != "http://localhost:4001" || provider.Token != "litellm-token" {
+		t.Errorf("Unexpected provider config: %+v", provider)
+	}
+	if _, exists := provider.EnvVars["NO_PROXY"]; exists {
+		t.Error("NO_PROXY should have been unset")
+	}
+
+	settings, err := LoadSettings(defaultSettingsPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := settings.Env["HTTP_PROXY"]; got != "http://proxy:3128" {
+		t.Errorf("Expected settings to be regenerated for the active provider, got %v", got)
+	}
+}
+
+func TestProviderEditRejectsInvalidInput(t *testing.T) {
+	setupTestHome(t)
+
+	for _, tt := range []struct{ flag, value string }{
+		{"base-url", "ftp://example.com"},
+		{"env", "NOVALUE"},
+		{"env", "ANTHROPIC_AUTH_TOKEN=x"},
+		{"unset", "base_url"},
+		{"timeout", "-1s"},
+	} {
+		t.Run(tt.flag, func(t *testing.T) {
+			resetFlags(t, providerEditCmd, tt.flag)
+			_ = providerEditCmd.Flags().Set(tt.flag, tt.value)
+			if err := runProviderEdit(providerEditCmd, []string{glmProvider}); err == nil {
+				t.Errorf("Expected --%s %s to be rejected", tt.flag, tt.value)
+			}
+			cfg, err := config.LoadConfig()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, exists := cfg.Providers[glmProvider].EnvVars[config.EnvAuthToken]; exists {
+				t.Errorf("Expected --%s %s to leave the config unchanged", tt.flag, tt.value)
+			}
+		})
+	}
+}
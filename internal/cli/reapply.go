@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/vanducng/cflip/internal/config"
+)
+
+// reapplyCmd regenerates Claude settings for the active provider
+var reapplyCmd = &cobra.Command{
+	Use:   "reapply",
+	Short: "Regenerate Claude settings for the active provider",
+	Long: `Write the settings for the active provider to ~/.claude/settings.json
+again, for example after another tool overwrote them. A snapshot of the
+current settings is taken first. Nothing is prompted for and the active
+provider does not change.`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         runReapply,
+}
+
+func init() {
+	reapplyCmd.Flags().String("settings-path", "", "Claude settings file to write (default ~/.claude/settings.json)")
+	reapplyCmd.Flags().Bool("no-snapshot", false, "Do not snapshot the current settings before writing")
+}
+
+// NewReapplyCmd exports the reapply command
+func NewReapplyCmd() *cobra.Command {
+	return reapplyCmd
+}
+
+func runReapply(cmd *cobra.Command, args []string) error {
+	verbose, _ := cmd.Flags().GetBool("verbose")
+	quiet, _ := cmd.Flags().GetBool("quiet")
+	noSnapshot, _ := cmd.Flags().GetBool("no-snapshot")
+	settingsPath := resolveSettingsPath(cmd)
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if _, err := cfg.GetActiveProvider(); err != nil {
+		return err
+	}
+
+	var before *ClaudeSettings
+	if verbose && !quiet {
+		before, _ = LoadSettings(settingsPath)
+	}
+
+	if err := generateClaudeSettings(cfg, settingsPath, noSnapshot, verbose, quiet); err != nil {
+		return fmt.Errorf("failed to generate Claude settings: %w", err)
+	}
+
+	if !quiet {
+		displayName, _ := getProviderDisplayInfo(cfg.Provider, cfg.Providers[cfg.Provider])
+		fmt.Printf("%s Reapplied %s to %s\n", checkMark(), displayName, settingsPath)
+	}
+	if before != nil {
+		if after, err := LoadSettings(settingsPath); err == nil {
+			printSettingsChanges(diffSettings(before, after))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/vanducng/cflip/internal/config"
+)
+
+func TestReapplyRestoresClobberedSettings(t *testing.T) {
+	setupTestHome(t)
+	withEmptyStdin(t)
+
+	if err := runSwitch(switchCmd, []string{glmProvider}); err != nil {
+		t.Fatalf("switch failed: %v", err)
+	}
+
+	// Another tool rewrites the env but leaves its own settings behind
+	settingsPath := defaultSettingsPath()
+	settings, err := LoadSettings(settingsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	settings.Env = map[string]interface{}{config.EnvBaseURL: "https://other.example.com", "KEEP": "me"}
+	if err := SaveSettings(settingsPath, settings); err != nil {
+		t.Fatal(err)
+	}
+
+	captureStdout(t, func() error {
+		return runReapply(reapplyCmd, nil)
+	})
+
+	settings, err = LoadSettings(settingsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := settings.Env[config.EnvBaseURL]; got != "https://api.z.ai/api/anthropic" {
+		t.Errorf("Expected the glm base URL to be restored, got %v", got)
+	}
+	if got := settings.Env[config.EnvAuthToken]; got != "glm-test-token" {
+		t.Errorf("Expected the glm token to be restored, got %v", got)
+	}
+	if got := settings.Env["KEEP"]; got != "me" {
+		t.Errorf("Expected unrelated env vars to be kept, got %v", got)
+	}
+
+	// The clobbered state is kept as a snapshot
+	snapshots, err := ListSnapshots(snapshotsDirFor(settingsPath))
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, name := range snapshots {
+		if provider, _, ok := parseSnapshotName(name); ok && provider == "external" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected a snapshot of the clobbered settings, got %v", snapshots)
+	}
+}
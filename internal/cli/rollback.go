@@ -0,0 +1,155 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/vanducng/cflip/internal/config"
+)
+
+// rollbackFileName holds the name of the snapshot taken before the settings
+// were last written and, on a second line, the provider active then. It has
+// no .json extension so it is not listed as a snapshot.
+const rollbackFileName = "rollback"
+
+// rollbackCmd undoes the last settings write by restoring the snapshot taken
+// before it
+var rollbackCmd = &cobra.Command{
+	Use:   "rollback",
+	Short: "Undo the last settings write, restoring the previous settings file",
+	Long: `Restore the snapshot cflip took before it last wrote the Claude settings
+and make the provider that was active then active again, so rolling back a
+switch switches back while rolling back a reapply or a base URL change
+keeps the provider. The settings being replaced are snapshotted first, so
+running rollback twice toggles between the two states.
+
+Unlike undo, which regenerates the settings, rollback puts back the exact
+file, including any other changes made to it since.`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         runRollback,
+}
+
+func init() {
+	rollbackCmd.Flags().String("settings-path", "", "Claude settings file to restore (default ~/.claude/settings.json)")
+}
+
+// NewRollbackCmd exports the rollback command
+func NewRollbackCmd() *cobra.Command {
+	return rollbackCmd
+}
+
+func runRollback(cmd *cobra.Command, args []string) error {
+	quiet, _ := cmd.Flags().GetBool("quiet")
+	settingsPath := resolveSettingsPath(cmd)
+	snapshotsDir := snapshotsDirFor(settingsPath)
+
+	snapshotName, recordedProvider, err := readRollbackPoint(snapshotsDir)
+	if err != nil {
+		return err
+	}
+
+	// Refuse to restore snapshots that don't parse
+	snapshot, err := LoadSettings(filepath.Join(snapshotsDir, snapshotName))
+	if err != nil {
+		return fmt.Errorf("refusing to restore %s: %w", snapshotName, err)
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	before, err := LoadSettings(settingsPath)
+	if err != nil {
+		return fmt.Errorf("failed to load current settings: %w", err)
+	}
+
+	// Keep the current state so a second rollback comes back to it
+	current, err := snapshotCurrentSettings(settingsPath, snapshotsDir, before)
+	if err != nil {
+		return fmt.Errorf("failed to snapshot current settings: %w", err)
+	}
+
+	if err := SaveSettings(settingsPath, snapshot); err != nil {
+		return err
+	}
+
+	// Model mappings belong to the provider, so switching back restores them
+	oldProvider := cfg.Provider
+	if _, exists := cfg.Providers[recordedProvider]; exists && recordedProvider != oldProvider {
+		if err := cfg.SetActiveProvider(recordedProvider); err != nil {
+			return err
+		}
+		if err := config.SaveConfig(cfg); err != nil {
+			return fmt.Errorf("failed to save configuration: %w", err)
+		}
+	}
+
+	if err := recordRollbackPoint(snapshotsDir, current, oldProvider); err != nil {
+		return err
+	}
+
+	if !quiet {
+		fmt.Printf("%s Restored %s to %s\n", checkMark(), snapshotName, settingsPath)
+		printSettingsChanges(diffSettings(before, snapshot))
+		if cfg.Provider != oldProvider {
+			fmt.Printf("Active provider: %s → %s\n", oldProvider, cfg.Provider)
+		}
+	}
+	return nil
+}
+
+// snapshotCurrentSettings snapshots settings, the current contents of
+// settingsPath, and returns the snapshot's name. When the latest snapshot
+// is identical it is reused.
+func snapshotCurrentSettings(settingsPath, snapshotsDir string, settings *ClaudeSettings) (string, error) {
+	provider := detectCurrentProvider(settings)
+	name, err := CreateSnapshot(settingsPath, snapshotsDir, provider)
+	if err != nil || name != "" {
+		return name, err
+	}
+	return latestSnapshot(snapshotsDir, provider)
+}
+
+// recordRollbackPoint remembers the snapshot rollback restores and the
+// provider it makes active
+func recordRollbackPoint(snapshotsDir, snapshotName, provider string) error {
+	data := []byte(snapshotName + "\n" + provider + "\n")
+	if err := os.WriteFile(filepath.Join(snapshotsDir, rollbackFileName), data, 0600); err != nil {
+		return fmt.Errorf("failed to record rollback point: %w", err)
+	}
+	return nil
+}
+
+// clearRollbackPoint forgets the rollback point, used when settings are
+// written without a snapshot and the recorded one no longer applies
+func clearRollbackPoint(snapshotsDir string) {
+	_ = os.Remove(filepath.Join(snapshotsDir, rollbackFileName))
+}
+
+// readRollbackPoint returns the snapshot rollback restores and the provider
+// it makes active. The provider is empty for points recorded without one,
+// which leave the active provider alone.
+func readRollbackPoint(snapshotsDir string) (string, string, error) {
+	data, err := os.ReadFile(filepath.Join(snapshotsDir, rollbackFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", "", fmt.Errorf("no previous state recorded; run 'cflip snapshot list' and restore one with 'cflip snapshot restore'")
+		}
+		return "", "", fmt.Errorf("failed to read rollback point: %w", err)
+	}
+
+	name, provider, _ := strings.Cut(strings.TrimSpace(string(data)), "\n")
+	name, provider = strings.TrimSpace(name), strings.TrimSpace(provider)
+	if _, _, ok := parseSnapshotName(name); !ok || filepath.Base(name) != name {
+		return "", "", fmt.Errorf("invalid rollback point '%s'; run 'cflip snapshot list' and restore one with 'cflip snapshot restore'", name)
+	}
+	if _, err := os.Stat(filepath.Join(snapshotsDir, name)); err != nil {
+		return "", "", fmt.Errorf("snapshot %s is no longer available; run 'cflip snapshot list' and restore one with 'cflip snapshot restore'", name)
+	}
+	return name, provider, nil
+}
@@ -0,0 +1,129 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/vanducng/cflip/internal/config"
+)
+
+func TestRollbackTogglesLastSwitch(t *testing.T) {
+	setupTestHome(t)
+	withEmptyStdin(t)
+
+	settingsPath := defaultSettingsPath()
+	writeTestSettings(t, settingsPath, map[string]interface{}{"KEEP": "me"})
+	original, err := LoadSettings(settingsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runSwitch(switchCmd, []string{glmProvider}); err != nil {
+		t.Fatalf("switch failed: %v", err)
+	}
+	switched, err := LoadSettings(settingsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertState := func(wantSettings *ClaudeSettings, wantProvider string) {
+		t.Helper()
+		settings, err := LoadSettings(settingsPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !settingsEqual(settings, wantSettings) {
+			t.Errorf("Expected settings env %v, got %v", wantSettings.Env, settings.Env)
+		}
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cfg.Provider != wantProvider {
+			t.Errorf("Expected active provider %s, got %s", wantProvider, cfg.Provider)
+		}
+	}
+
+	out := captureStdout(t, func() error { return runRollback(rollbackCmd, nil) })
+	if !strings.Contains(out, "Active provider: glm → anthropic") {
+		t.Errorf("Expected the provider change to be reported, got:\n%s", out)
+	}
+	assertState(original, anthropicProvider)
+
+	// A second rollback goes back to the switched state
+	captureStdout(t, func() error { return runRollback(rollbackCmd, nil) })
+	assertState(switched, glmProvider)
+}
+
+func TestRollbackAfterBaseURLChangeKeepsProvider(t *testing.T) {
+	setupTestHome(t)
+	withEmptyStdin(t)
+
+	if err := runSwitch(switchCmd, []string{glmProvider}); err != nil {
+		t.Fatalf("switch failed: %v", err)
+	}
+	settingsPath := defaultSettingsPath()
+	switched, err := LoadSettings(settingsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	captureStdout(t, func() error {
+		return runConfigSetBaseURL(configSetBaseURLCmd, []string{glmProvider, "https://open.bigmodel.cn/api/anthropic"})
+	})
+
+	out := captureStdout(t, func() error { return runRollback(rollbackCmd, nil) })
+	if strings.Contains(out, "Active provider:") {
+		t.Errorf("Expected the provider to be left alone, got:\n%s", out)
+	}
+
+	settings, err := LoadSettings(settingsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !settingsEqual(settings, switched) {
+		t.Errorf("Expected settings env %v, got %v", switched.Env, settings.Env)
+	}
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Provider != glmProvider {
+		t.Errorf("Expected glm to stay active, got %s", cfg.Provider)
+	}
+}
+
+func TestRollbackWithoutRecordedState(t *testing.T) {
+	setupTestHome(t)
+
+	err := runRollback(rollbackCmd, nil)
+	if err == nil || !strings.Contains(err.Error(), "cflip snapshot list") {
+		t.Errorf("Expected an error pointing to cflip snapshot list, got %v", err)
+	}
+}
+
+func TestSwitchWithoutSnapshotClearsRollbackPoint(t *testing.T) {
+	setupTestHome(t)
+	withEmptyStdin(t)
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Provider = glmProvider
+	settingsPath := defaultSettingsPath()
+	if err := generateClaudeSettings(cfg, settingsPath, false, false, true); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := readRollbackPoint(snapshotsDirFor(settingsPath)); err != nil {
+		t.Fatalf("Expected a rollback point after writing settings: %v", err)
+	}
+
+	// The recorded snapshot no longer precedes the live settings
+	if err := generateClaudeSettings(cfg, settingsPath, true, false, true); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := readRollbackPoint(snapshotsDirFor(settingsPath)); err == nil {
+		t.Error("Expected the rollback point to be cleared by a write without a snapshot")
+	}
+}
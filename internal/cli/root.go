@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/spf13/cobra"
+	"github.com/vanducng/cflip/internal/config"
 )
 
 var (
@@ -27,8 +28,12 @@ Claude Code providers (Anthropic, GLM/z.ai, and future providers).
 
 It manages the ~/.claude/settings.json configuration file to toggle between
 different API endpoints and authentication methods.`,
+	PersistentPreRunE: persistentPreRun,
 }
 
+// configFile is the --config flag value
+var configFile string
+
 // Execute adds all child commands to the root command and sets flags appropriately.
 func Execute(v, c, bt string) error {
 	// Set build information (override version if provided)
@@ -44,21 +49,65 @@ func Execute(v, c, bt string) error {
 	// Add subcommands
 	addCommands()
 
+	defer unlockConfig()
 	return rootCmd.Execute()
 }
 
-// addCommands adds all subcommands to the root command
+// persistentPreRun applies the global flags and takes the config lock
+// before any command runs
+func persistentPreRun(cmd *cobra.Command, args []string) error {
+	config.SetConfigPath(configFile)
+	return lockConfigForCommand(cmd, args)
+}
+
+// ExitError ends cflip with Code without printing an error message
+type ExitError struct {
+	Code int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.Code)
+}
+
+// errChangesPending is returned by checks such as switch --dry-run when
+// running the command for real would change something
+var errChangesPending = &ExitError{Code: 2}
+
+// addCommands adds all subcommands to the root command.
+// Commands are listed in help in the order they are added here.
 func addCommands() {
 	// Main commands
 	rootCmd.AddCommand(newSwitchCmd())
-	rootCmd.AddCommand(editCmd)
+	rootCmd.AddCommand(NewStatusCmd())
 	rootCmd.AddCommand(NewListCmd())
+	rootCmd.AddCommand(editCmd)
+	rootCmd.AddCommand(NewUndoCmd())
+	rootCmd.AddCommand(NewReapplyCmd())
+	rootCmd.AddCommand(NewRollbackCmd())
+	rootCmd.AddCommand(NewCurrentCmd())
+	rootCmd.AddCommand(NewEnvCmd())
+	rootCmd.AddCommand(NewAutoSwitchCmd())
+	rootCmd.AddCommand(NewConfigCmd())
+	rootCmd.AddCommand(NewValidateCmd())
+	rootCmd.AddCommand(NewDoctorCmd())
+	rootCmd.AddCommand(NewTestCmd())
+	rootCmd.AddCommand(NewMigratePathsCmd())
+	rootCmd.AddCommand(NewSnapshotCmd())
+	rootCmd.AddCommand(NewProviderCmd())
+	rootCmd.AddCommand(NewProfileCmd())
+	rootCmd.AddCommand(NewImportCmd())
+	rootCmd.AddCommand(NewCompletionCmd())
 }
 
 func init() {
+	// Keep the most used commands first in help output
+	cobra.EnableCommandSorting = false
+
 	// Global flags
 	rootCmd.PersistentFlags().BoolP("verbose", "v", false, "verbose output")
 	rootCmd.PersistentFlags().BoolP("quiet", "q", false, "quiet mode (no output)")
+	rootCmd.PersistentFlags().StringVar(&configFile, "config", "", "cflip config file (default in $XDG_CONFIG_HOME/cflip or ~/.cflip, see cflip status; also honors CFLIP_CONFIG)")
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "disable colored output (also honors NO_COLOR)")
 
 	// Custom help and version formatting
 	cobra.AddTemplateFunc("indent", indent)
@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/vanducng/cflip/internal/config"
+)
+
+var registerOnce sync.Once
+
+// registerCommands attaches subcommands to rootCmd once per test binary
+func registerCommands() {
+	registerOnce.Do(addCommands)
+}
+
+func TestSubcommandsRegistered(t *testing.T) {
+	registerCommands()
+
+	for _, name := range []string{"switch", "status", "list", "edit"} {
+		t.Run(name, func(t *testing.T) {
+			cmd, _, err := rootCmd.Find([]string{name})
+			if err != nil {
+				t.Fatalf("Failed to resolve %s: %v", name, err)
+			}
+			if cmd == nil || cmd == rootCmd {
+				t.Fatalf("Expected %s to resolve to a subcommand, got root", name)
+			}
+
+			// Persistent flags must be inherited from the root command
+			for _, flag := range []string{"verbose", "quiet"} {
+				if cmd.Flags().Lookup(flag) == nil && cmd.InheritedFlags().Lookup(flag) == nil {
+					t.Errorf("Expected %s to inherit --%s", name, flag)
+				}
+			}
+		})
+	}
+}
+
+func TestSubcommandsHelpAndOrder(t *testing.T) {
+	registerCommands()
+
+	expected := []string{"switch", "status", "list", "edit"}
+	commands := rootCmd.Commands()
+	if len(commands) < len(expected) {
+		t.Fatalf("Expected at least %d commands, got %d", len(expected), len(commands))
+	}
+	for i, name := range expected {
+		if commands[i].Name() != name {
+			t.Errorf("Expected command %d to be %s, got %s", i, name, commands[i].Name())
+		}
+	}
+
+	for _, name := range expected {
+		t.Run(name, func(t *testing.T) {
+			var out bytes.Buffer
+			rootCmd.SetOut(&out)
+			rootCmd.SetArgs([]string{name, "--help"})
+			t.Cleanup(func() {
+				rootCmd.SetOut(nil)
+				rootCmd.SetArgs(nil)
+			})
+
+			if err := rootCmd.Execute(); err != nil {
+				t.Fatalf("%s --help failed: %v", name, err)
+			}
+			if !strings.Contains(out.String(), "cflip "+name) {
+				t.Errorf("Expected help for %s, got:\n%s", name, out.String())
+			}
+		})
+	}
+}
+
+func TestConfigFlagKeepsHomeUntouched(t *testing.T) {
+	registerCommands()
+	home := isolateHome(t)
+	resetFlags(t, providerAddCmd, "base-url")
+
+	configPath := filepath.Join(t.TempDir(), "config.toml")
+	t.Cleanup(func() {
+		configFile = ""
+		config.SetConfigPath("")
+		rootCmd.SetArgs(nil)
+	})
+
+	for _, args := range [][]string{
+		{"--config", configPath, "-q", "provider", "add", "foo", "--base-url", "https://foo.example.com"},
+		{"--config", configPath, "-q", "provider", "rename", "foo", "bar"},
+	} {
+		rootCmd.SetArgs(args)
+		err := rootCmd.Execute()
+		unlockConfig()
+		if err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+
+	cfg, err := config.LoadConfigFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, exists := cfg.Providers["bar"]; !exists {
+		t.Errorf("Expected provider bar in %s, got %v", configPath, cfg.Providers)
+	}
+	if _, err := os.Stat(filepath.Join(home, ".cflip")); !os.IsNotExist(err) {
+		t.Error("Commands run with --config must not touch ~/.cflip")
+	}
+}
@@ -1,13 +1,18 @@
 package cli
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"slices"
 	"sort"
 	"strings"
 	"time"
+
+	"github.com/spf13/cobra"
 )
 
 // ClaudeSettings represents the full Claude settings structure
@@ -16,6 +21,55 @@ type ClaudeSettings struct {
 	Env    map[string]interface{} `json:"env,omitempty"`
 	// Preserve all other fields
 	AdditionalFields map[string]interface{} `json:"-"`
+	// keyOrder is the order of the top-level keys in the loaded file
+	keyOrder []string
+}
+
+// claudeConfigDirEnvVar is the env var Claude Code reads its config directory from
+const claudeConfigDirEnvVar = "CLAUDE_CONFIG_DIR"
+
+// settingsPathEnvVar overrides the Claude settings file for every command
+const settingsPathEnvVar = "CFLIP_SETTINGS_PATH"
+
+// defaultSettingsPath returns the default Claude settings file location
+func defaultSettingsPath() string {
+	path, _ := defaultSettingsPathSource()
+	return path
+}
+
+// defaultSettingsPathSource returns the default Claude settings file location
+// and where it came from: CFLIP_SETTINGS_PATH, CLAUDE_CONFIG_DIR or the
+// default ~/.claude
+func defaultSettingsPathSource() (path, source string) {
+	if path := os.Getenv(settingsPathEnvVar); path != "" {
+		return path, settingsPathEnvVar
+	}
+	if dir := os.Getenv(claudeConfigDirEnvVar); dir != "" {
+		return filepath.Join(dir, "settings.json"), claudeConfigDirEnvVar
+	}
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".claude", "settings.json"), "default"
+}
+
+// resolveSettingsPath returns the settings path for a command, honoring the
+// --settings-path flag when the command defines it
+func resolveSettingsPath(cmd *cobra.Command) string {
+	path, _ := resolveSettingsPathSource(cmd)
+	return path
+}
+
+// resolveSettingsPathSource returns the settings path for a command together
+// with where it came from
+func resolveSettingsPathSource(cmd *cobra.Command) (path, source string) {
+	if flag := cmd.Flag("settings-path"); flag != nil && flag.Value.String() != "" {
+		return flag.Value.String(), "--settings-path"
+	}
+	return defaultSettingsPathSource()
+}
+
+// snapshotsDirFor returns the snapshots directory that sits next to a settings file
+func snapshotsDirFor(settingsPath string) string {
+	return filepath.Join(filepath.Dir(settingsPath), "snapshots")
 }
 
 // LoadSettings loads the current Claude settings
@@ -60,10 +114,41 @@ func LoadSettings(settingsPath string) (*ClaudeSettings, error) {
 		}
 	}
 
+	// Remember the key order so saving does not reshuffle the file
+	settings.keyOrder = topLevelKeys(data)
+
 	return &settings, nil
 }
 
-// SaveSettings saves settings preserving all fields
+// topLevelKeys returns the keys of a JSON object in the order they appear
+func topLevelKeys(data []byte) []string {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil
+	}
+
+	var keys []string
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil
+		}
+		key, ok := tok.(string)
+		if !ok {
+			return nil
+		}
+		var skip json.RawMessage
+		if err := dec.Decode(&skip); err != nil {
+			return nil
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// SaveSettings saves settings preserving all fields. Keys keep the order
+// they were loaded in; new keys follow with $schema first, env second and
+// the rest sorted, so saving unchanged settings never changes the file.
 func SaveSettings(settingsPath string, settings *ClaudeSettings) error {
 	// Build the full settings map
 	fullSettings := make(map[string]interface{})
@@ -84,7 +169,7 @@ func SaveSettings(settingsPath string, settings *ClaudeSettings) error {
 	}
 
 	// Marshal with indentation
-	data, err := json.MarshalIndent(fullSettings, "", "  ")
+	data, err := marshalOrdered(fullSettings, orderedKeys(fullSettings, settings.keyOrder))
 	if err != nil {
 		return fmt.Errorf("failed to marshal settings: %w", err)
 	}
@@ -94,39 +179,200 @@ func SaveSettings(settingsPath string, settings *ClaudeSettings) error {
 		return fmt.Errorf("failed to create settings directory: %w", err)
 	}
 
-	// Write file
-	if err := os.WriteFile(settingsPath, data, 0600); err != nil {
+	// Write to a temp file and rename so readers never see a partial file
+	return writeFileAtomic(settingsPath, data)
+}
+
+// orderedKeys returns the keys of fields in their loaded order, followed by
+// any new keys: $schema, then env, then the rest sorted
+func orderedKeys(fields map[string]interface{}, loaded []string) []string {
+	keys := make([]string, 0, len(fields))
+	seen := make(map[string]bool, len(fields))
+	for _, key := range loaded {
+		if _, ok := fields[key]; ok && !seen[key] {
+			keys = append(keys, key)
+			seen[key] = true
+		}
+	}
+
+	var added []string
+	for key := range fields {
+		if !seen[key] {
+			added = append(added, key)
+		}
+	}
+	sort.Slice(added, func(i, j int) bool {
+		ri, rj := keyRank(added[i]), keyRank(added[j])
+		if ri != rj {
+			return ri < rj
+		}
+		return added[i] < added[j]
+	})
+
+	// A new $schema or env goes to the top even when the file had other keys
+	for len(added) > 0 && keyRank(added[0]) < 2 {
+		keys = insertLeading(keys, added[0])
+		added = added[1:]
+	}
+	return append(keys, added...)
+}
+
+// keyRank orders $schema before env before everything else
+func keyRank(key string) int {
+	switch key {
+	case "$schema":
+		return 0
+	case "env":
+		return 1
+	default:
+		return 2
+	}
+}
+
+// insertLeading places a new $schema first and a new env right after $schema
+func insertLeading(keys []string, key string) []string {
+	i := 0
+	if key == "env" {
+		i = slices.Index(keys, "$schema") + 1
+	}
+	return slices.Insert(keys, i, key)
+}
+
+// marshalOrdered writes fields as an indented JSON object with its top-level
+// keys in the given order. Nested objects are written with sorted keys.
+func marshalOrdered(fields map[string]interface{}, keys []string) ([]byte, error) {
+	if len(keys) == 0 {
+		return []byte("{}"), nil
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("{\n")
+	for i, key := range keys {
+		name, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.MarshalIndent(fields[key], "  ", "  ")
+		if err != nil {
+			return nil, err
+		}
+		buf.WriteString("  ")
+		buf.Write(name)
+		buf.WriteString(": ")
+		buf.Write(value)
+		if i < len(keys)-1 {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\n")
+	}
+	buf.WriteString("}")
+	return buf.Bytes(), nil
+}
+
+// writeFileAtomic replaces path with data via a synced temp file in the same
+// directory, keeping the mode of an existing file. A failed write leaves
+// the original file untouched. When path is a symlink, as with dotfile
+// managers, the file it points to is replaced and the link is kept.
+func writeFileAtomic(path string, data []byte) error {
+	path, err := resolveSymlinks(path)
+	if err != nil {
 		return fmt.Errorf("failed to write settings: %w", err)
 	}
+	dir := filepath.Dir(path)
+
+	mode := os.FileMode(0600)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	tmpFile, err := os.CreateTemp(dir, ".settings-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write settings: %w", err)
+	}
+	tmpPath := tmpFile.Name()
+
+	if err := writeAndSync(tmpFile, data, mode); err != nil {
+		tmpFile.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write settings: %w", err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write settings: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write settings: %w", err)
+	}
+
+	// Persist the rename itself; not every platform can sync a directory
+	if d, err := os.Open(dir); err == nil {
+		_ = d.Sync()
+		d.Close()
+	}
 
 	return nil
 }
 
-// CreateSnapshot creates a snapshot of current settings, skipping if identical to latest
-func CreateSnapshot(settingsPath, snapshotsDir, provider string) error {
+// resolveSymlinks returns the file path ends up at. A path that does not
+// exist yet is returned as is, and a dangling link resolves to its target.
+func resolveSymlinks(path string) (string, error) {
+	target, err := filepath.EvalSymlinks(path)
+	if err == nil {
+		return target, nil
+	}
+	if !os.IsNotExist(err) {
+		return "", err
+	}
+
+	link, err := os.Readlink(path)
+	if err != nil {
+		return path, nil
+	}
+	if !filepath.IsAbs(link) {
+		link = filepath.Join(filepath.Dir(path), link)
+	}
+	return link, nil
+}
+
+// writeAndSync writes data to f, sets its mode and flushes it to disk
+func writeAndSync(f *os.File, data []byte, mode os.FileMode) error {
+	if _, err := f.Write(data); err != nil {
+		return err
+	}
+	if err := f.Chmod(mode); err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+// CreateSnapshot creates a snapshot of current settings and returns its name.
+// The name is empty when the latest snapshot is identical and none was taken.
+func CreateSnapshot(settingsPath, snapshotsDir, provider string) (string, error) {
 	// Load current settings
 	settings, err := LoadSettings(settingsPath)
 	if err != nil {
-		return fmt.Errorf("failed to load settings for snapshot: %w", err)
+		return "", fmt.Errorf("failed to load settings for snapshot: %w", err)
 	}
 
 	// Ensure snapshots directory exists
 	if err := os.MkdirAll(snapshotsDir, 0750); err != nil {
-		return fmt.Errorf("failed to create snapshots directory: %w", err)
+		return "", fmt.Errorf("failed to create snapshots directory: %w", err)
 	}
 
 	// Check if the latest snapshot for this provider is identical
 	if isIdenticalToLatestSnapshot(snapshotsDir, provider, settings) {
 		// Skip creating duplicate snapshot
-		return nil
+		return "", nil
 	}
 
 	// Create snapshot file name
 	timestamp := time.Now().Format("20060102-150405")
-	snapshotFile := filepath.Join(snapshotsDir, fmt.Sprintf("snapshot-%s-%s.json", provider, timestamp))
+	name := fmt.Sprintf("snapshot-%s-%s.json", provider, timestamp)
 
 	// Save snapshot
-	return SaveSettings(snapshotFile, settings)
+	return name, SaveSettings(filepath.Join(snapshotsDir, name), settings)
 }
 
 // ListSnapshots lists all available snapshots
@@ -149,42 +395,43 @@ func ListSnapshots(snapshotsDir string) ([]string, error) {
 	return snapshots, nil
 }
 
-// CleanupOldSnapshots removes old snapshots keeping only the most recent N per provider
-func CleanupOldSnapshots(snapshotsDir string, keepCount int) error {
+// CleanupOldSnapshots removes old snapshots keeping only the most recent N per provider.
+// It returns the names of the deleted snapshots.
+func CleanupOldSnapshots(snapshotsDir string, keepCount int) ([]string, error) {
 	snapshots, err := ListSnapshots(snapshotsDir)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Group snapshots by provider
 	providerSnapshots := make(map[string][]string)
 	for _, snapshot := range snapshots {
-		// Extract provider from filename: snapshot-provider-timestamp.json
-		parts := filepath.Base(snapshot)
-		if len(parts) > len("snapshot-") {
-			provider := parts[9:] // Remove "snapshot-" prefix
-			if idx := findIndex(provider, '-'); idx > 0 {
-				provider = provider[:idx]
-				providerSnapshots[provider] = append(providerSnapshots[provider], snapshot)
-			}
+		if provider, _, ok := parseSnapshotName(snapshot); ok {
+			providerSnapshots[provider] = append(providerSnapshots[provider], snapshot)
 		}
 	}
 
 	// Remove old snapshots
+	var deleted []string
 	for _, files := range providerSnapshots {
 		if len(files) <= keepCount {
 			continue
 		}
 
-		// Sort files by timestamp (newest first)
-		// For simplicity, just remove the oldest files
+		// Sort files by timestamp (newest first) and remove everything past keepCount
+		sort.Slice(files, func(i, j int) bool {
+			return extractTimestampFromFilename(files[i]) > extractTimestampFromFilename(files[j])
+		})
 		for i := keepCount; i < len(files); i++ {
 			filePath := filepath.Join(snapshotsDir, files[i])
-			os.Remove(filePath)
+			if err := os.Remove(filePath); err == nil {
+				deleted = append(deleted, files[i])
+			}
 		}
 	}
 
-	return nil
+	sort.Strings(deleted)
+	return deleted, nil
 }
 
 // isIdenticalToLatestSnapshot checks if current settings match the latest snapshot for a provider
@@ -198,7 +445,7 @@ func isIdenticalToLatestSnapshot(snapshotsDir, provider string, currentSettings
 	// Filter snapshots by provider and sort by timestamp (descending)
 	var providerSnapshots []string
 	for _, snapshot := range snapshots {
-		if strings.HasPrefix(snapshot, fmt.Sprintf("snapshot-%s-", provider)) {
+		if snapshotProvider, _, ok := parseSnapshotName(snapshot); ok && snapshotProvider == provider {
 			providerSnapshots = append(providerSnapshots, snapshot)
 		}
 	}
@@ -230,14 +477,25 @@ func isIdenticalToLatestSnapshot(snapshotsDir, provider string, currentSettings
 
 // extractTimestampFromFilename extracts timestamp from snapshot filename
 func extractTimestampFromFilename(filename string) string {
-	// Format: snapshot-provider-timestamp.json
-	parts := strings.Split(filename, "-")
-	if len(parts) >= 3 {
-		// Remove .json extension
-		timestamp := strings.Join(parts[2:], "-")
-		return strings.TrimSuffix(timestamp, ".json")
-	}
-	return ""
+	_, timestamp, _ := parseSnapshotName(filename)
+	return timestamp
+}
+
+// parseSnapshotName splits snapshot-<provider>-<date>-<time>.json into provider and timestamp.
+// The timestamp is always the last two dash-separated fields, so provider names may contain dashes.
+func parseSnapshotName(name string) (provider, timestamp string, ok bool) {
+	if !strings.HasPrefix(name, "snapshot-") || !strings.HasSuffix(name, ".json") {
+		return "", "", false
+	}
+
+	parts := strings.Split(strings.TrimSuffix(strings.TrimPrefix(name, "snapshot-"), ".json"), "-")
+	if len(parts) < 3 {
+		return "", "", false
+	}
+
+	provider = strings.Join(parts[:len(parts)-2], "-")
+	timestamp = strings.Join(parts[len(parts)-2:], "-")
+	return provider, timestamp, true
 }
 
 // settingsEqual compares two ClaudeSettings structs
@@ -270,20 +528,7 @@ func settingsEqual(a, b *ClaudeSettings) bool {
 	return true
 }
 
-// compareValues compares two interface{} values
+// compareValues compares two decoded JSON values structurally, including nested maps and slices
 func compareValues(a, b interface{}) bool {
-	// Simple string comparison for common cases
-	if fmt.Sprintf("%v", a) == fmt.Sprintf("%v", b) {
-		return true
-	}
-	return false
-}
-
-func findIndex(s string, sep rune) int {
-	for i, r := range s {
-		if r == sep {
-			return i
-		}
-	}
-	return -1
+	return reflect.DeepEqual(a, b)
 }
@@ -0,0 +1,360 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"sort"
+	"testing"
+)
+
+func TestCleanupOldSnapshotsKeepsNewest(t *testing.T) {
+	snapshotsDir := t.TempDir()
+
+	// Written out of chronological order on purpose
+	for _, name := range []string{
+		"snapshot-glm-20240301-120000.json",
+		"snapshot-glm-20240101-120000.json",
+		"snapshot-glm-20240501-120000.json",
+		"snapshot-glm-20240201-120000.json",
+		"snapshot-glm-20240401-120000.json",
+	} {
+		writeTestSettings(t, filepath.Join(snapshotsDir, name), map[string]interface{}{})
+	}
+
+	deleted, err := CleanupOldSnapshots(snapshotsDir, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deleted) != 3 || deleted[0] != "snapshot-glm-20240101-120000.json" {
+		t.Errorf("Expected the three oldest snapshots to be reported, got %v", deleted)
+	}
+
+	remaining, err := ListSnapshots(snapshotsDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(remaining)
+
+	want := []string{"snapshot-glm-20240401-120000.json", "snapshot-glm-20240501-120000.json"}
+	if len(remaining) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, remaining)
+	}
+	for i := range want {
+		if remaining[i] != want[i] {
+			t.Errorf("Expected %v, got %v", want, remaining)
+		}
+	}
+}
+
+func TestParseSnapshotName(t *testing.T) {
+	tests := []struct {
+		name          string
+		wantProvider  string
+		wantTimestamp string
+		wantOK        bool
+	}{
+		{"snapshot-glm-20240101-120000.json", "glm", "20240101-120000", true},
+		{"snapshot-claude-code-20240101-120000.json", "claude-code", "20240101-120000", true},
+		{"snapshot-my-custom-proxy-20240101-120000.json", "my-custom-proxy", "20240101-120000", true},
+		{"snapshot-20240101.json", "", "", false},
+		{"settings.json", "", "", false},
+	}
+
+	for _, tt := range tests {
+		provider, timestamp, ok := parseSnapshotName(tt.name)
+		if provider != tt.wantProvider || timestamp != tt.wantTimestamp || ok != tt.wantOK {
+			t.Errorf("parseSnapshotName(%q) = (%q, %q, %t), want (%q, %q, %t)",
+				tt.name, provider, timestamp, ok, tt.wantProvider, tt.wantTimestamp, tt.wantOK)
+		}
+	}
+}
+
+func TestCleanupOldSnapshotsDashedProviders(t *testing.T) {
+	snapshotsDir := t.TempDir()
+
+	for _, name := range []string{
+		"snapshot-claude-code-20240101-120000.json",
+		"snapshot-claude-code-20240201-120000.json",
+		"snapshot-my-custom-proxy-20240101-120000.json",
+		"snapshot-my-custom-proxy-20240201-120000.json",
+	} {
+		writeTestSettings(t, filepath.Join(snapshotsDir, name), map[string]interface{}{})
+	}
+
+	if _, err := CleanupOldSnapshots(snapshotsDir, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	remaining, err := ListSnapshots(snapshotsDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(remaining)
+
+	want := []string{
+		"snapshot-claude-code-20240201-120000.json",
+		"snapshot-my-custom-proxy-20240201-120000.json",
+	}
+	if len(remaining) != len(want) || remaining[0] != want[0] || remaining[1] != want[1] {
+		t.Errorf("Expected %v, got %v", want, remaining)
+	}
+}
+
+func TestIdenticalSnapshotIgnoresProviderPrefixes(t *testing.T) {
+	snapshotsDir := t.TempDir()
+	settings := &ClaudeSettings{Env: map[string]interface{}{"A": "1"}}
+
+	// A claude-code snapshot must not count as the latest "claude" snapshot
+	writeTestSettings(t, filepath.Join(snapshotsDir, "snapshot-claude-code-20240101-120000.json"), settings.Env)
+
+	if isIdenticalToLatestSnapshot(snapshotsDir, "claude", settings) {
+		t.Error("claude-code snapshot should not match provider claude")
+	}
+	if !isIdenticalToLatestSnapshot(snapshotsDir, "claude-code", settings) {
+		t.Error("Expected identical claude-code snapshot to be detected")
+	}
+}
+
+func TestSaveSettingsPreservesUnknownFields(t *testing.T) {
+	settingsPath := filepath.Join(t.TempDir(), "settings.json")
+	original := `{
+  "$schema": "https://json.schemastore.org/claude-code-settings.json",
+  "env": {"ANTHROPIC_BASE_URL": "https://api.z.ai/api/anthropic", "API_TIMEOUT_MS": 3000000},
+  "permissions": {"allow": ["Bash(go test:*)"], "deny": []},
+  "hooks": {"PostToolUse": [{"matcher": "Edit", "hooks": [{"type": "command", "command": "gofmt -w ."}]}]},
+  "statusLine": {"type": "command", "command": "cflip current"},
+  "includeCoAuthoredBy": false
+}`
+	if err := os.WriteFile(settingsPath, []byte(original), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	settings, err := LoadSettings(settingsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveSettings(settingsPath, settings); err != nil {
+		t.Fatal(err)
+	}
+
+	saved, err := os.ReadFile(settingsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var want, got map[string]interface{}
+	if err := json.Unmarshal([]byte(original), &want); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(saved, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("Round trip changed the settings\n--- want ---\n%s\n--- got ---\n%s", original, saved)
+	}
+}
+
+func TestSaveSettingsKeepsKeyOrder(t *testing.T) {
+	settingsPath := filepath.Join(t.TempDir(), "settings.json")
+	original := `{
+  "statusLine": {"type": "command", "command": "cflip current"},
+  "$schema": "https://json.schemastore.org/claude-code-settings.json",
+  "permissions": {"allow": ["Bash(go test:*)"]},
+  "env": {"API_TIMEOUT_MS": "3000000"},
+  "includeCoAuthoredBy": false
+}`
+	if err := os.WriteFile(settingsPath, []byte(original), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// Saving unchanged settings twice must give byte-identical files
+	var saved [2][]byte
+	for i := range saved {
+		settings, err := LoadSettings(settingsPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := SaveSettings(settingsPath, settings); err != nil {
+			t.Fatal(err)
+		}
+		if saved[i], err = os.ReadFile(settingsPath); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if string(saved[0]) != string(saved[1]) {
+		t.Errorf("Saving twice changed the file\n--- first ---\n%s\n--- second ---\n%s", saved[0], saved[1])
+	}
+
+	want := []string{"statusLine", "$schema", "permissions", "env", "includeCoAuthoredBy"}
+	if got := topLevelKeys(saved[1]); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected keys %v, got %v", want, got)
+	}
+}
+
+func TestSaveSettingsOrdersNewKeys(t *testing.T) {
+	settingsPath := filepath.Join(t.TempDir(), "settings.json")
+	settings := &ClaudeSettings{
+		Schema: "https://json.schemastore.org/claude-code-settings.json",
+		Env:    map[string]interface{}{"ANTHROPIC_BASE_URL": "https://api.z.ai/api/anthropic"},
+		AdditionalFields: map[string]interface{}{
+			"statusLine":  map[string]interface{}{"type": "command"},
+			"permissions": map[string]interface{}{},
+			"model":       "sonnet",
+		},
+	}
+	if err := SaveSettings(settingsPath, settings); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(settingsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"$schema", "env", "model", "permissions", "statusLine"}
+	if got := topLevelKeys(data); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected keys %v, got %v", want, got)
+	}
+
+	// A file without env gets it right after $schema
+	loaded, err := LoadSettings(settingsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded.keyOrder = []string{"model", "$schema"}
+	if err := SaveSettings(settingsPath, loaded); err != nil {
+		t.Fatal(err)
+	}
+	if data, err = os.ReadFile(settingsPath); err != nil {
+		t.Fatal(err)
+	}
+	want = []string{"model", "$schema", "env", "permissions", "statusLine"}
+	if got := topLevelKeys(data); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected keys %v, got %v", want, got)
+	}
+}
+
+func TestSaveSettingsKeepsFileMode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file modes are not preserved on Windows")
+	}
+
+	settingsPath := filepath.Join(t.TempDir(), "settings.json")
+	if err := os.WriteFile(settingsPath, []byte(`{}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SaveSettings(settingsPath, &ClaudeSettings{Env: map[string]interface{}{"KEY": "value"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Stat(settingsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0644 {
+		t.Errorf("Expected mode 0644 to be kept, got %v", info.Mode().Perm())
+	}
+}
+
+func TestSaveSettingsKeepsSymlink(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("creating symlinks needs extra privileges on Windows")
+	}
+
+	// A dotfile manager keeps the real file elsewhere and links to it
+	dotfiles := t.TempDir()
+	target := filepath.Join(dotfiles, "settings.json")
+	if err := os.WriteFile(target, []byte(`{}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	claudeDir := t.TempDir()
+	settingsPath := filepath.Join(claudeDir, "settings.json")
+	if err := os.Symlink(target, settingsPath); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SaveSettings(settingsPath, &ClaudeSettings{Env: map[string]interface{}{"KEY": "value"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Lstat(settingsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode()&os.ModeSymlink == 0 {
+		t.Fatal("Expected the settings file to stay a symlink")
+	}
+	settings, err := LoadSettings(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if settings.Env["KEY"] != "value" {
+		t.Errorf("Expected the link target to be written, got %v", settings.Env)
+	}
+	if entries, _ := os.ReadDir(claudeDir); len(entries) != 1 {
+		t.Errorf("Expected no temp files next to the link, got %d entries", len(entries))
+	}
+}
+
+func TestSaveSettingsFailureKeepsOriginal(t *testing.T) {
+	dir := t.TempDir()
+	settingsPath := filepath.Join(dir, "settings.json")
+	original := []byte(`{"env": {"KEEP": "me"}}`)
+	if err := os.WriteFile(settingsPath, original, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	assertUntouched := func(t *testing.T) {
+		t.Helper()
+		data, err := os.ReadFile(settingsPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != string(original) {
+			t.Errorf("Original settings were modified: %s", data)
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) != 1 {
+			t.Errorf("Expected no temp files to be left behind, got %d entries", len(entries))
+		}
+	}
+
+	t.Run("unmarshalable settings", func(t *testing.T) {
+		err := SaveSettings(settingsPath, &ClaudeSettings{Env: map[string]interface{}{"BAD": make(chan int)}})
+		if err == nil {
+			t.Fatal("Expected the save to fail")
+		}
+		assertUntouched(t)
+	})
+
+	t.Run("unwritable directory", func(t *testing.T) {
+		if runtime.GOOS == "windows" || os.Geteuid() == 0 {
+			t.Skip("directory permissions are not enforced here")
+		}
+		if err := os.Chmod(dir, 0500); err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { _ = os.Chmod(dir, 0700) })
+
+		if err := SaveSettings(settingsPath, &ClaudeSettings{Env: map[string]interface{}{"NEW": "value"}}); err == nil {
+			t.Fatal("Expected the save to fail")
+		}
+		assertUntouched(t)
+	})
+}
+
+func TestSnapshotsDirUsesPlatformSeparator(t *testing.T) {
+	settingsPath := filepath.Join("home", "me", ".claude", "settings.json")
+	sep := string(filepath.Separator)
+
+	want := "home" + sep + "me" + sep + ".claude" + sep + "snapshots"
+	if got := snapshotsDirFor(settingsPath); got != want {
+		t.Errorf("Expected %s, got %s", want, got)
+	}
+}
@@ -0,0 +1,538 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// snapshotCmd groups commands that work with settings snapshots
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Manage snapshots of the Claude settings file",
+	Long: `Snapshots of ~/.claude/settings.json are taken automatically before every
+switch and stored in ~/.claude/snapshots.`,
+}
+
+// snapshotRestoreCmd restores a snapshot over the live settings file
+var snapshotRestoreCmd = &cobra.Command{
+	Use:   "restore [filename|index]",
+	Short: "Restore a snapshot to the Claude settings file",
+	Long: `Restore a snapshot by file name, by its index in the snapshot list, or
+with --latest (optionally limited to one provider with --provider).
+A snapshot of the current settings is taken first so the restore can be undone.`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeSnapshotNames(1),
+	RunE:              runSnapshotRestore,
+}
+
+// snapshotListCmd lists snapshots newest first
+var snapshotListCmd = &cobra.Command{
+	Use:     "list",
+	Short:   "List snapshots, newest first",
+	Aliases: []string{"ls"},
+	Args:    cobra.NoArgs,
+	RunE:    runSnapshotList,
+}
+
+// snapshotDiffCmd compares snapshots with each other or with the live settings
+var snapshotDiffCmd = &cobra.Command{
+	Use:   "diff [snapshot] [other]",
+	Short: "Show differences between a snapshot and the live settings",
+	Long: `Compare a snapshot (default: the latest) with the live Claude settings,
+or two snapshots with each other. Snapshots may be given by file name or index.
+API tokens are masked except for their first characters.`,
+	Args:              cobra.MaximumNArgs(2),
+	ValidArgsFunction: completeSnapshotNames(2),
+	RunE:              runSnapshotDiff,
+}
+
+// snapshotShowCmd prints the env of a snapshot with secrets masked
+var snapshotShowCmd = &cobra.Command{
+	Use:   "show <filename|index>",
+	Short: "Show the contents of a snapshot",
+	Long: `Show the provider and environment variables stored in a snapshot, given by
+file name or index. API tokens are masked except for their first characters,
+also in --json output.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeSnapshotNames(1),
+	RunE:              runSnapshotShow,
+}
+
+// snapshotExportCmd copies a snapshot out of the snapshots directory
+var snapshotExportCmd = &cobra.Command{
+	Use:   "export <filename|index>",
+	Short: "Copy a snapshot to another location",
+	Long: `Copy a snapshot, given by file name or index, to --output so it can be
+archived elsewhere. When --output is a directory the snapshot keeps its name.
+The copy is not masked; it holds the same API tokens as the snapshot.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeSnapshotNames(1),
+	RunE:              runSnapshotExport,
+}
+
+// snapshotImportCmd adds an external settings file as a new snapshot
+var snapshotImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Add a settings file as a new snapshot",
+	Long: `Copy a Claude settings JSON file into the snapshots directory under a new
+snapshot name. The provider in the name is detected from its contents, and
+the file must parse as Claude settings to be accepted.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSnapshotImport,
+}
+
+func init() {
+	snapshotCmd.PersistentFlags().String("settings-path", "", "Claude settings file (default ~/.claude/settings.json)")
+	snapshotRestoreCmd.Flags().Bool("latest", false, "Restore the newest snapshot")
+	snapshotRestoreCmd.Flags().String("provider", "", "Only consider snapshots detected as this provider: "+strings.Join(snapshotProviders, ", "))
+	_ = snapshotRestoreCmd.RegisterFlagCompletionFunc("provider", fixedCompletion(snapshotProviders...))
+
+	snapshotListCmd.Flags().BoolP("json", "j", false, "Output in JSON format")
+	snapshotShowCmd.Flags().BoolP("json", "j", false, "Output in JSON format")
+	snapshotExportCmd.Flags().StringP("output", "o", "", "File or directory to copy the snapshot to (required)")
+	_ = snapshotExportCmd.MarkFlagRequired("output")
+
+	snapshotCmd.AddCommand(snapshotListCmd)
+	snapshotCmd.AddCommand(snapshotShowCmd)
+	snapshotCmd.AddCommand(snapshotRestoreCmd)
+	snapshotCmd.AddCommand(snapshotDiffCmd)
+	snapshotCmd.AddCommand(snapshotExportCmd)
+	snapshotCmd.AddCommand(snapshotImportCmd)
+}
+
+// NewSnapshotCmd exports the snapshot command
+func NewSnapshotCmd() *cobra.Command {
+	return snapshotCmd
+}
+
+// snapshotProviders are the names detectCurrentProvider gives snapshots.
+// Custom providers are detected as external.
+var snapshotProviders = []string{"anthropic", "glm", "openai", "external", "unknown"}
+
+// snapshotTimeFormat is the timestamp layout used in snapshot file names
+const snapshotTimeFormat = "20060102-150405"
+
+// snapshotInfo describes a snapshot file for listing
+type snapshotInfo struct {
+	Index     int       `json:"index"`
+	Name      string    `json:"name"`
+	Provider  string    `json:"provider"`
+	Timestamp time.Time `json:"timestamp"`
+	Size      int64     `json:"size"`
+	Identical bool      `json:"identicalToCurrent"`
+}
+
+// listSnapshotInfo returns snapshot details sorted newest first, comparing each to the live settings
+func listSnapshotInfo(settingsPath string) ([]snapshotInfo, error) {
+	snapshotsDir := snapshotsDirFor(settingsPath)
+	snapshots, err := ListSnapshots(snapshotsDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+
+	current, err := LoadSettings(settingsPath)
+	if err != nil {
+		current = nil
+	}
+
+	var infos []snapshotInfo
+	for _, name := range snapshots {
+		provider, timestamp, ok := parseSnapshotName(name)
+		if !ok {
+			continue
+		}
+
+		info := snapshotInfo{Name: name, Provider: provider}
+		if t, err := time.ParseInLocation(snapshotTimeFormat, timestamp, time.Local); err == nil {
+			info.Timestamp = t
+		}
+		path := filepath.Join(snapshotsDir, name)
+		if stat, err := os.Stat(path); err == nil {
+			info.Size = stat.Size()
+		}
+		if current != nil {
+			if snapshot, err := LoadSettings(path); err == nil {
+				info.Identical = settingsEqual(current, snapshot)
+			}
+		}
+		infos = append(infos, info)
+	}
+
+	sort.SliceStable(infos, func(i, j int) bool {
+		return infos[i].Timestamp.After(infos[j].Timestamp)
+	})
+	for i := range infos {
+		infos[i].Index = i + 1
+	}
+
+	return infos, nil
+}
+
+func runSnapshotList(cmd *cobra.Command, args []string) error {
+	jsonOutput, _ := cmd.Flags().GetBool("json")
+	return printSnapshotList(resolveSettingsPath(cmd), jsonOutput)
+}
+
+// printSnapshotList prints the snapshots for a settings file as text or JSON
+func printSnapshotList(settingsPath string, jsonOutput bool) error {
+	infos, err := listSnapshotInfo(settingsPath)
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput {
+		if infos == nil {
+			infos = []snapshotInfo{}
+		}
+		data, err := json.MarshalIndent(infos, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal snapshots: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	if len(infos) == 0 {
+		fmt.Println("No snapshots found")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "#\tPROVIDER\tCREATED\tSIZE\tCURRENT")
+	for _, info := range infos {
+		current := "-"
+		if info.Identical {
+			current = "identical"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%d B\t%s\n",
+			info.Index, info.Provider, info.Timestamp.Format("2006-01-02 15:04:05"), info.Size, current)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Printf("\nSnapshots directory: %s\n", snapshotsDirFor(settingsPath))
+	return nil
+}
+
+// snapshotContents is the masked content of a snapshot for show output
+type snapshotContents struct {
+	Name     string            `json:"name"`
+	Provider string            `json:"provider"`
+	Env      map[string]string `json:"env"`
+}
+
+func runSnapshotShow(cmd *cobra.Command, args []string) error {
+	jsonOutput, _ := cmd.Flags().GetBool("json")
+	settingsPath := resolveSettingsPath(cmd)
+
+	name, err := resolveSnapshotName(settingsPath, args[0])
+	if err != nil {
+		return err
+	}
+
+	settings, err := LoadSettings(filepath.Join(snapshotsDirFor(settingsPath), name))
+	if err != nil {
+		return fmt.Errorf("failed to load snapshot %s: %w", name, err)
+	}
+
+	contents := snapshotContents{
+		Name:     name,
+		Provider: detectCurrentProvider(settings),
+		Env:      make(map[string]string, len(settings.Env)),
+	}
+	for key, value := range settings.Env {
+		contents.Env[key] = displayValue(key, value)
+	}
+
+	if jsonOutput {
+		data, err := json.MarshalIndent(contents, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal snapshot: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	fmt.Printf("Snapshot: %s\n", contents.Name)
+	fmt.Printf("Provider: %s\n", contents.Provider)
+	if len(contents.Env) == 0 {
+		fmt.Println("\nNo environment variables")
+		return nil
+	}
+
+	keys := make([]string, 0, len(contents.Env))
+	for key := range contents.Env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	fmt.Println()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tVALUE")
+	for _, key := range keys {
+		fmt.Fprintf(w, "%s\t%s\n", key, contents.Env[key])
+	}
+	return w.Flush()
+}
+
+func runSnapshotRestore(cmd *cobra.Command, args []string) error {
+	quiet, _ := cmd.Flags().GetBool("quiet")
+	latest, _ := cmd.Flags().GetBool("latest")
+	provider, _ := cmd.Flags().GetString("provider")
+	settingsPath := resolveSettingsPath(cmd)
+	snapshotsDir := snapshotsDirFor(settingsPath)
+
+	if provider != "" && !slices.Contains(snapshotProviders, provider) {
+		return fmt.Errorf("no snapshots can match provider '%s'; snapshots are named after the provider detected from the settings (%s), so custom providers are external",
+			provider, strings.Join(snapshotProviders, ", "))
+	}
+
+	var snapshotName string
+	var err error
+	switch {
+	case len(args) == 1:
+		snapshotName, err = resolveSnapshotName(settingsPath, args[0])
+	case latest || provider != "":
+		snapshotName, err = latestSnapshot(snapshotsDir, provider)
+	default:
+		return fmt.Errorf("specify a snapshot name or index, or use --latest")
+	}
+	if err != nil {
+		return err
+	}
+
+	before, err := LoadSettings(settingsPath)
+	if err != nil {
+		return fmt.Errorf("failed to load current settings: %w", err)
+	}
+
+	if err := restoreSnapshot(settingsPath, snapshotsDir, snapshotName); err != nil {
+		return err
+	}
+
+	if !quiet {
+		fmt.Printf("%s Restored %s to %s\n", checkMark(), snapshotName, settingsPath)
+		after, err := LoadSettings(settingsPath)
+		if err == nil {
+			printSettingsChanges(diffSettings(before, after))
+		}
+	}
+	return nil
+}
+
+func runSnapshotDiff(cmd *cobra.Command, args []string) error {
+	settingsPath := resolveSettingsPath(cmd)
+	snapshotsDir := snapshotsDirFor(settingsPath)
+
+	var names []string
+	for _, arg := range args {
+		name, err := resolveSnapshotName(settingsPath, arg)
+		if err != nil {
+			return err
+		}
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		name, err := latestSnapshot(snapshotsDir, "")
+		if err != nil {
+			return err
+		}
+		names = append(names, name)
+	}
+
+	from, err := LoadSettings(filepath.Join(snapshotsDir, names[0]))
+	if err != nil {
+		return fmt.Errorf("failed to load snapshot %s: %w", names[0], err)
+	}
+
+	toLabel := settingsPath
+	var to *ClaudeSettings
+	if len(names) == 2 {
+		toLabel = names[1]
+		to, err = LoadSettings(filepath.Join(snapshotsDir, names[1]))
+	} else {
+		to, err = LoadSettings(settingsPath)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load %s: %w", toLabel, err)
+	}
+
+	changes := diffSettings(from, to)
+	fmt.Printf("--- %s\n+++ %s\n", names[0], toLabel)
+	if len(changes) == 0 {
+		fmt.Println("No differences")
+		return nil
+	}
+	printSettingsChanges(changes)
+	return nil
+}
+
+func runSnapshotExport(cmd *cobra.Command, args []string) error {
+	quiet, _ := cmd.Flags().GetBool("quiet")
+	output, _ := cmd.Flags().GetString("output")
+	settingsPath := resolveSettingsPath(cmd)
+
+	name, err := resolveSnapshotName(settingsPath, args[0])
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(filepath.Join(snapshotsDirFor(settingsPath), name))
+	if err != nil {
+		return fmt.Errorf("failed to read snapshot %s: %w", name, err)
+	}
+
+	if info, err := os.Stat(output); err == nil && info.IsDir() {
+		output = filepath.Join(output, name)
+	}
+	if err := os.WriteFile(output, data, 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", output, err)
+	}
+
+	if !quiet {
+		fmt.Printf("%s Exported %s to %s\n", checkMark(), name, output)
+	}
+	return nil
+}
+
+func runSnapshotImport(cmd *cobra.Command, args []string) error {
+	quiet, _ := cmd.Flags().GetBool("quiet")
+	settingsPath := resolveSettingsPath(cmd)
+
+	name, err := importSnapshot(snapshotsDirFor(settingsPath), args[0], time.Now())
+	if err != nil {
+		return err
+	}
+
+	if !quiet {
+		fmt.Printf("%s Imported %s as %s\n", checkMark(), args[0], name)
+	}
+	return nil
+}
+
+// importSnapshot copies a settings file into snapshotsDir under a snapshot
+// name built from its detected provider and now, returning that name
+func importSnapshot(snapshotsDir, path string, now time.Time) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if !json.Valid(data) {
+		return "", fmt.Errorf("%s is not valid JSON", path)
+	}
+	settings, err := LoadSettings(path)
+	if err != nil {
+		return "", fmt.Errorf("%s is not a Claude settings file: %w", path, err)
+	}
+
+	name := fmt.Sprintf("snapshot-%s-%s.json", detectCurrentProvider(settings), now.Format(snapshotTimeFormat))
+	target := filepath.Join(snapshotsDir, name)
+	if _, err := os.Stat(target); err == nil {
+		return "", fmt.Errorf("snapshot %s already exists; try again in a second", name)
+	}
+
+	if err := os.MkdirAll(snapshotsDir, 0750); err != nil {
+		return "", fmt.Errorf("failed to create snapshots directory: %w", err)
+	}
+	if err := os.WriteFile(target, data, 0600); err != nil {
+		return "", fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	return name, nil
+}
+
+// latestSnapshot returns the newest snapshot, optionally limited to one provider
+func latestSnapshot(snapshotsDir, provider string) (string, error) {
+	snapshots, err := ListSnapshots(snapshotsDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to list snapshots: %w", err)
+	}
+
+	var candidates []string
+	for _, snapshot := range snapshots {
+		snapshotProvider, _, ok := parseSnapshotName(snapshot)
+		if ok && (provider == "" || snapshotProvider == provider) {
+			candidates = append(candidates, snapshot)
+		}
+	}
+
+	if len(candidates) == 0 {
+		if provider != "" {
+			return "", fmt.Errorf("no snapshots found for provider '%s'", provider)
+		}
+		return "", fmt.Errorf("no snapshots found in %s", snapshotsDir)
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return extractTimestampFromFilename(candidates[i]) > extractTimestampFromFilename(candidates[j])
+	})
+
+	return candidates[0], nil
+}
+
+// resolveSnapshotName maps a file name or 1-based list index to a snapshot file name
+func resolveSnapshotName(settingsPath, arg string) (string, error) {
+	snapshotsDir := snapshotsDirFor(settingsPath)
+
+	snapshots, err := ListSnapshots(snapshotsDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to list snapshots: %w", err)
+	}
+
+	if index, err := strconv.Atoi(arg); err == nil {
+		// Indexes follow the newest-first order of snapshot list
+		infos, err := listSnapshotInfo(settingsPath)
+		if err != nil {
+			return "", err
+		}
+		if index < 1 || index > len(infos) {
+			return "", fmt.Errorf("snapshot index %d out of range (1-%d)", index, len(infos))
+		}
+		return infos[index-1].Name, nil
+	}
+
+	// Only plain file names inside the snapshots directory are accepted
+	if arg != filepath.Base(arg) || strings.Contains(arg, "..") || strings.ContainsAny(arg, `/\`) {
+		return "", fmt.Errorf("invalid snapshot name '%s'", arg)
+	}
+
+	for _, snapshot := range snapshots {
+		if snapshot == arg {
+			return snapshot, nil
+		}
+	}
+
+	return "", fmt.Errorf("snapshot '%s' not found in %s", arg, snapshotsDir)
+}
+
+// restoreSnapshot snapshots the current settings and then writes the chosen snapshot over them
+func restoreSnapshot(settingsPath, snapshotsDir, snapshotName string) error {
+	// Refuse to restore snapshots that don't parse
+	snapshot, err := LoadSettings(filepath.Join(snapshotsDir, snapshotName))
+	if err != nil {
+		return fmt.Errorf("refusing to restore %s: %w", snapshotName, err)
+	}
+
+	// Keep the current state so the restore itself can be undone
+	if _, err := os.Stat(settingsPath); err == nil {
+		current, err := LoadSettings(settingsPath)
+		if err != nil {
+			return fmt.Errorf("failed to load current settings: %w", err)
+		}
+		if _, err := CreateSnapshot(settingsPath, snapshotsDir, detectCurrentProvider(current)); err != nil {
+			return fmt.Errorf("failed to snapshot current settings: %w", err)
+		}
+	}
+
+	return SaveSettings(settingsPath, snapshot)
+}
@@ -0,0 +1,285 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeTestSettings saves settings with the given env to path
+func writeTestSettings(t *testing.T, path string, env map[string]interface{}) {
+	t.Helper()
+	if err := SaveSettings(path, &ClaudeSettings{Env: env}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSnapshotRestore(t *testing.T) {
+	dir := t.TempDir()
+	settingsPath := filepath.Join(dir, "settings.json")
+	snapshotsDir := snapshotsDirFor(settingsPath)
+
+	writeTestSettings(t, filepath.Join(snapshotsDir, "snapshot-glm-20240101-120000.json"),
+		map[string]interface{}{"ANTHROPIC_BASE_URL": "https://api.z.ai/api/anthropic"})
+	writeTestSettings(t, settingsPath, map[string]interface{}{"ANTHROPIC_AUTH_TOKEN": "sk-ant-test"})
+
+	name, err := resolveSnapshotName(settingsPath, "snapshot-glm-20240101-120000.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := restoreSnapshot(settingsPath, snapshotsDir, name); err != nil {
+		t.Fatal(err)
+	}
+
+	settings, err := LoadSettings(settingsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if settings.Env["ANTHROPIC_BASE_URL"] != "https://api.z.ai/api/anthropic" {
+		t.Errorf("Snapshot was not restored: %v", settings.Env)
+	}
+
+	// The pre-restore state must have been snapshotted
+	snapshots, err := ListSnapshots(snapshotsDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshots) != 2 {
+		t.Errorf("Expected a safety snapshot to be created, got %v", snapshots)
+	}
+}
+
+func TestSnapshotRestoreProviderFilter(t *testing.T) {
+	setupTestHome(t)
+	resetFlags(t, snapshotRestoreCmd, "provider")
+
+	settingsPath := defaultSettingsPath()
+	writeTestSettings(t, filepath.Join(snapshotsDirFor(settingsPath), "snapshot-external-20240101-120000.json"),
+		map[string]interface{}{"ANTHROPIC_BASE_URL": "https://proxy.example.com"})
+	writeTestSettings(t, settingsPath, map[string]interface{}{})
+
+	// Custom providers are snapshotted as external, so their names never match
+	_ = snapshotRestoreCmd.Flags().Set("provider", "myproxy")
+	if err := runSnapshotRestore(snapshotRestoreCmd, nil); err == nil || !strings.Contains(err.Error(), "external") {
+		t.Errorf("Expected an error pointing to external, got %v", err)
+	}
+
+	completions, _ := snapshotRestoreCmd.GetFlagCompletionFunc("provider")
+	values, _ := completions(snapshotRestoreCmd, nil, "")
+	if !slices.Contains(values, "external") || slices.Contains(values, "myproxy") {
+		t.Errorf("Expected completion of detectable providers, got %v", values)
+	}
+
+	_ = snapshotRestoreCmd.Flags().Set("provider", "external")
+	captureStdout(t, func() error { return runSnapshotRestore(snapshotRestoreCmd, nil) })
+	settings, err := LoadSettings(settingsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if settings.Env["ANTHROPIC_BASE_URL"] != "https://proxy.example.com" {
+		t.Errorf("Expected the external snapshot to be restored, got %v", settings.Env)
+	}
+}
+
+func TestSnapshotRestoreRejectsTraversal(t *testing.T) {
+	settingsPath := filepath.Join(t.TempDir(), "settings.json")
+
+	for _, arg := range []string{"../settings.json", "..", "sub/snapshot.json"} {
+		if _, err := resolveSnapshotName(settingsPath, arg); err == nil {
+			t.Errorf("Expected %q to be rejected", arg)
+		}
+	}
+}
+
+func TestLatestSnapshot(t *testing.T) {
+	snapshotsDir := t.TempDir()
+	for _, name := range []string{
+		"snapshot-glm-20240101-120000.json",
+		"snapshot-glm-20240301-120000.json",
+		"snapshot-anthropic-20240401-120000.json",
+	} {
+		writeTestSettings(t, filepath.Join(snapshotsDir, name), map[string]interface{}{})
+	}
+
+	tests := []struct {
+		provider string
+		want     string
+	}{
+		{"", "snapshot-anthropic-20240401-120000.json"},
+		{"glm", "snapshot-glm-20240301-120000.json"},
+	}
+	for _, tt := range tests {
+		got, err := latestSnapshot(snapshotsDir, tt.provider)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("latestSnapshot(%q) = %s, want %s", tt.provider, got, tt.want)
+		}
+	}
+
+	if _, err := latestSnapshot(snapshotsDir, "missing"); err == nil {
+		t.Error("Expected error for provider without snapshots")
+	}
+}
+
+func TestSnapshotRestoreRefusesInvalidJSON(t *testing.T) {
+	dir := t.TempDir()
+	settingsPath := filepath.Join(dir, "settings.json")
+	snapshotsDir := snapshotsDirFor(settingsPath)
+
+	writeTestSettings(t, settingsPath, map[string]interface{}{"KEEP": "me"})
+	writeTestSettings(t, filepath.Join(snapshotsDir, "snapshot-glm-20240101-120000.json"), map[string]interface{}{})
+	if err := os.WriteFile(filepath.Join(snapshotsDir, "snapshot-glm-20240101-120000.json"), []byte("{broken"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := restoreSnapshot(settingsPath, snapshotsDir, "snapshot-glm-20240101-120000.json"); err == nil {
+		t.Fatal("Expected invalid snapshot to be refused")
+	}
+
+	settings, err := LoadSettings(settingsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if settings.Env["KEEP"] != "me" {
+		t.Error("Live settings must be untouched when a restore is refused")
+	}
+}
+
+func TestListSnapshotInfo(t *testing.T) {
+	dir := t.TempDir()
+	settingsPath := filepath.Join(dir, "settings.json")
+	snapshotsDir := snapshotsDirFor(settingsPath)
+
+	live := map[string]interface{}{"ANTHROPIC_BASE_URL": "https://api.z.ai/api/anthropic"}
+	writeTestSettings(t, settingsPath, live)
+	writeTestSettings(t, filepath.Join(snapshotsDir, "snapshot-anthropic-20240101-120000.json"), map[string]interface{}{})
+	writeTestSettings(t, filepath.Join(snapshotsDir, "snapshot-claude-code-20240301-120000.json"), live)
+
+	infos, err := listSnapshotInfo(settingsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(infos) != 2 {
+		t.Fatalf("Expected 2 snapshots, got %d", len(infos))
+	}
+
+	newest := infos[0]
+	if newest.Provider != "claude-code" || newest.Index != 1 {
+		t.Errorf("Expected newest snapshot for claude-code first, got %+v", newest)
+	}
+	if !newest.Identical {
+		t.Error("Expected newest snapshot to be identical to live settings")
+	}
+	if infos[1].Identical {
+		t.Error("Older snapshot should differ from live settings")
+	}
+
+	// Restore by index follows the same order
+	name, err := resolveSnapshotName(settingsPath, "2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "snapshot-anthropic-20240101-120000.json" {
+		t.Errorf("Index 2 resolved to %s", name)
+	}
+}
+
+func TestSnapshotShowMasksTokens(t *testing.T) {
+	setupTestHome(t)
+	resetFlags(t, snapshotShowCmd, "json")
+
+	const token = "sk-glm-secret-token-value"
+	snapshotsDir := snapshotsDirFor(defaultSettingsPath())
+	writeTestSettings(t, filepath.Join(snapshotsDir, "snapshot-glm-20240101-120000.json"), map[string]interface{}{
+		"ANTHROPIC_BASE_URL":   "https://api.z.ai/api/anthropic",
+		"ANTHROPIC_AUTH_TOKEN": token,
+	})
+
+	for _, jsonOutput := range []bool{false, true} {
+		_ = snapshotShowCmd.Flags().Set("json", boolString(jsonOutput))
+		out := captureStdout(t, func() error {
+			return runSnapshotShow(snapshotShowCmd, []string{"1"})
+		})
+
+		if strings.Contains(out, token) {
+			t.Errorf("json=%v: token must be masked in output:\n%s", jsonOutput, out)
+		}
+		if !strings.Contains(out, "https://api.z.ai/api/anthropic") {
+			t.Errorf("json=%v: expected the base URL in output:\n%s", jsonOutput, out)
+		}
+		if !strings.Contains(out, "glm") {
+			t.Errorf("json=%v: expected the detected provider in output:\n%s", jsonOutput, out)
+		}
+	}
+}
+
+func TestSnapshotExport(t *testing.T) {
+	setupTestHome(t)
+	settingsPath := defaultSettingsPath()
+	name := "snapshot-glm-20240101-120000.json"
+	writeTestSettings(t, filepath.Join(snapshotsDirFor(settingsPath), name),
+		map[string]interface{}{"ANTHROPIC_BASE_URL": "https://api.z.ai/api/anthropic"})
+
+	resetFlags(t, snapshotExportCmd, "output")
+
+	archive := t.TempDir()
+	_ = snapshotExportCmd.Flags().Set("output", archive)
+	captureStdout(t, func() error {
+		return runSnapshotExport(snapshotExportCmd, []string{"1"})
+	})
+
+	want, err := os.ReadFile(filepath.Join(snapshotsDirFor(settingsPath), name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(filepath.Join(archive, name))
+	if err != nil {
+		t.Fatalf("Expected the snapshot in %s: %v", archive, err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("Exported copy differs from the snapshot:\n%s", got)
+	}
+}
+
+func TestSnapshotImport(t *testing.T) {
+	snapshotsDir := filepath.Join(t.TempDir(), "snapshots")
+	external := filepath.Join(t.TempDir(), "backup.json")
+	writeTestSettings(t, external, map[string]interface{}{"ANTHROPIC_BASE_URL": "https://api.z.ai/api/anthropic"})
+
+	now := time.Date(2024, 3, 1, 9, 30, 0, 0, time.Local)
+	name, err := importSnapshot(snapshotsDir, external, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "snapshot-glm-20240301-093000.json" {
+		t.Errorf("Expected a glm snapshot named after the import time, got %s", name)
+	}
+	if provider, timestamp, ok := parseSnapshotName(name); !ok || provider != "glm" || timestamp != "20240301-093000" {
+		t.Errorf("Imported name does not parse as a snapshot: %s", name)
+	}
+
+	if _, err := importSnapshot(snapshotsDir, external, now); err == nil {
+		t.Error("Expected a second import in the same second to be refused")
+	}
+
+	invalid := filepath.Join(t.TempDir(), "broken.json")
+	if err := os.WriteFile(invalid, []byte(`{"env": `), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := importSnapshot(snapshotsDir, invalid, now.Add(time.Second)); err == nil {
+		t.Error("Expected invalid JSON to be rejected")
+	}
+
+	snapshots, err := ListSnapshots(snapshotsDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshots) != 1 {
+		t.Errorf("Expected only the valid import to be stored, got %v", snapshots)
+	}
+}
@@ -0,0 +1,176 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/vanducng/cflip/internal/config"
+)
+
+// statusCmd represents the status command
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the active provider and its configuration",
+	Long: `Show the active provider together with its auth mode, base URL and
+model mappings, and the config file they are read from. Use --json for
+scripts and shell prompts; API keys are never included, only whether one is
+configured.`,
+	Args: cobra.NoArgs,
+	RunE: runStatus,
+}
+
+func init() {
+	statusCmd.Flags().BoolP("json", "j", false, "Output in JSON format")
+}
+
+// NewStatusCmd exports the status command
+func NewStatusCmd() *cobra.Command {
+	return statusCmd
+}
+
+// statusOutput is the JSON form of status
+type statusOutput struct {
+	Provider         string            `json:"provider"`
+	DisplayName      string            `json:"displayName"`
+	AuthMode         string            `json:"authMode"`
+	APIKeyConfigured bool              `json:"apiKeyConfigured"`
+	APIKeySource     string            `json:"apiKeySource,omitempty"`
+	BaseURL          string            `json:"baseURL,omitempty"`
+	TimeoutMS        int               `json:"timeoutMs,omitempty"`
+	ModelMap         map[string]string `json:"modelMap,omitempty"`
+	SwitchedAt       time.Time         `json:"switchedAt,omitzero"`
+	LastValidated    time.Time         `json:"lastValidated,omitzero"`
+	Paths            statusPaths       `json:"paths"`
+}
+
+// statusPaths tells which config and settings files were used and why
+type statusPaths struct {
+	Config         string `json:"config"`
+	ConfigSource   string `json:"configSource"`
+	Settings       string `json:"settings"`
+	SettingsSource string `json:"settingsSource"`
+}
+
+// buildStatusOutput describes the active provider without its API key
+func buildStatusOutput(cfg *config.Config) statusOutput {
+	provider, fromEnv := cfg.ResolveProvider(cfg.Provider)
+	displayName, _ := getProviderDisplayInfo(cfg.Provider, provider)
+
+	out := statusOutput{
+		Provider:         cfg.Provider,
+		DisplayName:      displayName,
+		AuthMode:         config.AuthModeAPI,
+		APIKeyConfigured: provider.Token != "",
+		BaseURL:          provider.BaseURL,
+		ModelMap:         provider.ModelMap,
+		SwitchedAt:       cfg.SwitchedAt,
+		LastValidated:    provider.LastValidated,
+	}
+	if (!cfg.IsExternal(cfg.Provider) && !provider.UsesAPIKey()) || provider.AuthMode == config.AuthModeSubscription {
+		out.AuthMode = config.AuthModeSubscription
+	}
+	switch {
+	case fromEnv:
+		out.APIKeySource = "environment"
+	case provider.Token != "":
+		out.APIKeySource = "config"
+	}
+	if ms, err := strconv.Atoi(provider.EnvVars[timeoutEnvKey]); err == nil {
+		out.TimeoutMS = ms
+	}
+	return out
+}
+
+func runStatus(cmd *cobra.Command, args []string) error {
+	jsonOutput, _ := cmd.Flags().GetBool("json")
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	var paths statusPaths
+	paths.Config, paths.ConfigSource = config.ResolveConfigPath()
+	paths.Settings, paths.SettingsSource = resolveSettingsPathSource(cmd)
+
+	if jsonOutput {
+		out := buildStatusOutput(cfg)
+		out.Paths = paths
+		data, err := json.MarshalIndent(out, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal status: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	provider, fromEnv := cfg.ResolveProvider(cfg.Provider)
+	displayName, _ := getProviderDisplayInfo(cfg.Provider, provider)
+
+	fmt.Printf("Provider: %s (%s)\n", displayName, cfg.Provider)
+	fmt.Printf("Auth mode: %s\n", describeAuthMode(cfg.Provider, provider))
+	if fromEnv {
+		fmt.Printf("API key: from environment (%s)\n", config.APIKeyEnvVar(cfg.Provider))
+	}
+
+	if provider.BaseURL != "" {
+		fmt.Printf("Base URL: %s\n", provider.BaseURL)
+	}
+
+	if len(provider.ModelMap) > 0 {
+		fmt.Println("Model mappings:")
+		categories := make([]string, 0, len(provider.ModelMap))
+		for category := range provider.ModelMap {
+			categories = append(categories, category)
+		}
+		sort.Strings(categories)
+		for _, category := range categories {
+			fmt.Printf("  %s: %s\n", category, provider.ModelMap[category])
+		}
+	}
+
+	if !cfg.SwitchedAt.IsZero() {
+		fmt.Printf("Switched: %s\n", cfg.SwitchedAt.Local().Format("2006-01-02 15:04:05"))
+	}
+	if !provider.LastValidated.IsZero() {
+		fmt.Printf("Last validated: %s\n", provider.LastValidated.Local().Format("2006-01-02 15:04:05"))
+	}
+
+	fmt.Printf("Config file: %s (%s)\n", paths.Config, describePathSource(paths.ConfigSource))
+	fmt.Printf("Settings file: %s (%s)\n", paths.Settings, describePathSource(paths.SettingsSource))
+
+	return nil
+}
+
+// describePathSource explains where a resolved path came from
+func describePathSource(source string) string {
+	if source == "default" {
+		return source
+	}
+	return "from " + source
+}
+
+// describeAuthMode returns a human readable auth mode for a provider
+func describeAuthMode(providerName string, provider config.ProviderConfig) string {
+	if providerName != anthropicProvider {
+		if provider.AuthMode == config.AuthModeSubscription {
+			return statusSubscription
+		}
+		if provider.Token == "" {
+			return "API key (not configured)"
+		}
+		return "API key"
+	}
+
+	if provider.UsesAPIKey() {
+		return "API key"
+	}
+	if provider.Token != "" {
+		return "subscription (API key available)"
+	}
+	return statusSubscription
+}
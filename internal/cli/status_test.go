@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/vanducng/cflip/internal/config"
+)
+
+func TestStatusJSON(t *testing.T) {
+	setupTestHome(t)
+	withEmptyStdin(t)
+	resetFlags(t, statusCmd, "json")
+
+	if err := runSwitch(switchCmd, []string{glmProvider}); err != nil {
+		t.Fatalf("switch failed: %v", err)
+	}
+
+	_ = statusCmd.Flags().Set("json", "true")
+	out := captureStdout(t, func() error {
+		return runStatus(statusCmd, nil)
+	})
+
+	if strings.Contains(out, "glm-test-token") {
+		t.Fatalf("Status JSON must not contain the API key:\n%s", out)
+	}
+
+	var status statusOutput
+	if err := json.Unmarshal([]byte(out), &status); err != nil {
+		t.Fatalf("Invalid JSON: %v\n%s", err, out)
+	}
+	if status.Provider != glmProvider {
+		t.Errorf("Expected provider glm, got %s", status.Provider)
+	}
+	if !status.APIKeyConfigured || status.APIKeySource != "config" {
+		t.Errorf("Expected a configured key from the config, got %+v", status)
+	}
+	if status.AuthMode != config.AuthModeAPI {
+		t.Errorf("Expected auth mode api, got %s", status.AuthMode)
+	}
+	if status.SwitchedAt.IsZero() {
+		t.Error("Expected the switch time to be recorded")
+	}
+}
+
+func TestStatusJSONWithoutKey(t *testing.T) {
+	setupTestHome(t)
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	status := buildStatusOutput(cfg)
+	if status.Provider != anthropicProvider {
+		t.Errorf("Expected provider anthropic, got %s", status.Provider)
+	}
+	if status.APIKeyConfigured || status.APIKeySource != "" {
+		t.Errorf("Expected no API key, got %+v", status)
+	}
+	if status.AuthMode != config.AuthModeSubscription {
+		t.Errorf("Expected auth mode subscription, got %s", status.AuthMode)
+	}
+}
+
+func TestStatusReportsResolvedPaths(t *testing.T) {
+	home := setupTestHome(t)
+	resetFlags(t, statusCmd, "json")
+
+	claudeDir := filepath.Join(home, "claude-config")
+	t.Setenv(claudeConfigDirEnvVar, claudeDir)
+
+	out := captureStdout(t, func() error {
+		return runStatus(statusCmd, nil)
+	})
+	wantConfig := fmt.Sprintf("Config file: %s (default)", config.GetConfigPath())
+	if !strings.Contains(out, wantConfig) {
+		t.Errorf("Expected %q in:\n%s", wantConfig, out)
+	}
+	wantSettings := fmt.Sprintf("Settings file: %s (from CLAUDE_CONFIG_DIR)", filepath.Join(claudeDir, "settings.json"))
+	if !strings.Contains(out, wantSettings) {
+		t.Errorf("Expected %q in:\n%s", wantSettings, out)
+	}
+
+	configPath := filepath.Join(home, "other", "config.toml")
+	t.Setenv(config.ConfigEnvVar, configPath)
+
+	_ = statusCmd.Flags().Set("json", "true")
+	out = captureStdout(t, func() error {
+		return runStatus(statusCmd, nil)
+	})
+	var status statusOutput
+	if err := json.Unmarshal([]byte(out), &status); err != nil {
+		t.Fatalf("Invalid JSON: %v\n%s", err, out)
+	}
+	if status.Paths.Config != configPath || status.Paths.ConfigSource != config.ConfigEnvVar {
+		t.Errorf("Expected the config path from %s, got %+v", config.ConfigEnvVar, status.Paths)
+	}
+}
@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"os"
+
+	"github.com/charmbracelet/lipgloss"
+	"golang.org/x/term"
+)
+
+// noColor is set by the global --no-color flag
+var noColor bool
+
+var (
+	successStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("2"))
+	failureStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("1"))
+	warningStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("3"))
+)
+
+// colorEnabled reports whether output may contain colors. They are off with
+// --no-color, when NO_COLOR is set, or when stdout is not a terminal.
+func colorEnabled() bool {
+	if noColor || os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	return term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// colorize renders text in a style when colors are enabled
+func colorize(style lipgloss.Style, text string) string {
+	if !colorEnabled() {
+		return text
+	}
+	return style.Render(text)
+}
+
+// checkMark is the marker printed before successful results
+func checkMark() string {
+	return colorize(successStyle, "✓")
+}
+
+// crossMark is the marker printed before failed checks
+func crossMark() string {
+	return colorize(failureStyle, "✗")
+}
+
+// warningMark is the marker printed before warnings
+func warningMark() string {
+	return colorize(warningStyle, "!")
+}
@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMarkersWithoutColor(t *testing.T) {
+	t.Run("NO_COLOR", func(t *testing.T) {
+		t.Setenv("NO_COLOR", "1")
+		assertPlainMarkers(t)
+	})
+
+	t.Run("--no-color", func(t *testing.T) {
+		noColor = true
+		t.Cleanup(func() { noColor = false })
+		assertPlainMarkers(t)
+	})
+}
+
+// assertPlainMarkers checks that markers and command output carry no ANSI codes
+func assertPlainMarkers(t *testing.T) {
+	t.Helper()
+
+	if colorEnabled() {
+		t.Fatal("Expected colors to be disabled")
+	}
+	for _, marker := range []string{checkMark(), crossMark(), warningMark()} {
+		if strings.Contains(marker, "\x1b[") {
+			t.Errorf("Marker %q contains color codes", marker)
+		}
+	}
+
+	out := captureStdout(t, func() error {
+		printDoctorChecks([]doctorCheck{
+			{Name: "cflip config", OK: true, Detail: "ok"},
+			{Name: "claude binary", Detail: "missing", Hint: "install it"},
+		})
+		return nil
+	})
+	if strings.Contains(out, "\x1b[") {
+		t.Errorf("Output contains color codes:\n%q", out)
+	}
+	if !strings.Contains(out, "✓ cflip config") || !strings.Contains(out, "✗ claude binary") {
+		t.Errorf("Expected plain markers in output:\n%s", out)
+	}
+}
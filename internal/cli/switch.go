@@ -2,9 +2,9 @@ package cli
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"os"
-	"path/filepath"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -18,17 +18,21 @@ const (
 	anthropicName      = "Anthropic"
 	glmProvider        = "glm"
 	statusOAuth        = "OAuth"
+	statusSubscription = "subscription"
 	statusAPI          = "API"
 	currentMarker      = " [CURRENT]"
 	yesResponse        = "yes"
+	modelsClear        = "clear"
+	modelsRequired     = "required"
 )
 
 // switchCmd represents the switch command
 var switchCmd = &cobra.Command{
 	Use:   "switch [provider]",
 	Short: "Switch to a different Claude provider",
-	Long: `Switch the active Claude provider. This will update your ~/.cflip/config.toml
-file and generate the appropriate Claude settings for the specified provider.
+	Long: `Switch the active Claude provider. This will update your cflip config file
+(cflip status shows where it is) and generate the appropriate Claude
+settings for the specified provider.
 
 Available providers:
   anthropic - Official Anthropic Claude API (optional API key, uses default endpoint)
@@ -39,8 +43,24 @@ For external providers (glm, custom), you can optionally configure model mapping
 to map their models to Anthropic's model categories (haiku, sonnet, opus).
 
 If no provider is specified, you will be prompted to choose from the available options.`,
-	Args: cobra.MaximumNArgs(1),
-	RunE: runSwitch,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeProviderNames,
+	RunE:              runSwitch,
+}
+
+func init() {
+	switchCmd.Flags().String("models", modelsClear, "When the provider has no model mappings: clear or required")
+	switchCmd.Flags().String("auth", "", "Auth mode for anthropic: api or subscription")
+	switchCmd.Flags().String("settings-path", "", "Claude settings file to write (default ~/.claude/settings.json)")
+	switchCmd.Flags().Bool("dry-run", false, "Show the settings changes without writing anything")
+	switchCmd.Flags().Bool("previous", false, "Switch back to the previously active provider")
+	switchCmd.Flags().Bool("no-hooks", false, "Do not run the pre and post switch hooks")
+	switchCmd.Flags().Bool("no-snapshot", false, "Do not snapshot the current settings before writing")
+	switchCmd.Flags().BoolP("yes", "y", false, "Never prompt: use the provider as configured and fail if a token or base URL is missing")
+	switchCmd.Flags().Bool("no-input", false, "Same as --yes")
+
+	_ = switchCmd.RegisterFlagCompletionFunc("models", fixedCompletion(modelsClear, modelsRequired))
+	_ = switchCmd.RegisterFlagCompletionFunc("auth", fixedCompletion(config.AuthModeAPI, config.AuthModeSubscription))
 }
 
 func newSwitchCmd() *cobra.Command {
@@ -55,6 +75,27 @@ func NewSwitchCmd() *cobra.Command {
 func runSwitch(cmd *cobra.Command, args []string) error {
 	verbose, _ := cmd.Flags().GetBool("verbose")
 	quiet, _ := cmd.Flags().GetBool("quiet")
+	authMode, _ := cmd.Flags().GetString("auth")
+	modelsMode, _ := cmd.Flags().GetString("models")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	previous, _ := cmd.Flags().GetBool("previous")
+	noHooks, _ := cmd.Flags().GetBool("no-hooks")
+	noSnapshot, _ := cmd.Flags().GetBool("no-snapshot")
+	yes, _ := cmd.Flags().GetBool("yes")
+	noInput, _ := cmd.Flags().GetBool("no-input")
+	noInput = noInput || yes
+
+	if previous && len(args) > 0 {
+		return fmt.Errorf("--previous cannot be combined with a provider name")
+	}
+
+	if authMode != "" && authMode != config.AuthModeAPI && authMode != config.AuthModeSubscription {
+		return fmt.Errorf("invalid auth mode '%s' (use %s or %s)", authMode, config.AuthModeAPI, config.AuthModeSubscription)
+	}
+
+	if modelsMode != "" && modelsMode != modelsClear && modelsMode != modelsRequired {
+		return fmt.Errorf("invalid --models value '%s' (use %s or %s)", modelsMode, modelsClear, modelsRequired)
+	}
 
 	// Load configuration
 	cfg, err := config.LoadConfig()
@@ -62,6 +103,20 @@ func runSwitch(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to load configuration: %w", err)
 	}
 
+	if previous {
+		if cfg.PreviousProvider == "" {
+			return fmt.Errorf("no previous provider recorded")
+		}
+		if _, exists := cfg.Providers[cfg.PreviousProvider]; !exists {
+			return fmt.Errorf("previous provider '%s' is no longer configured", cfg.PreviousProvider)
+		}
+		args = []string{cfg.PreviousProvider}
+	}
+
+	if noInput && len(args) == 0 {
+		return fmt.Errorf("--yes needs a provider name: cflip switch <provider> --yes")
+	}
+
 	// Get provider name
 	providerName, err := getProviderName(args, cfg, verbose)
 	if err != nil {
@@ -77,27 +132,74 @@ func runSwitch(cmd *cobra.Command, args []string) error {
 		providerName = provider
 	}
 
-	// Check if already using this provider
-	if cfg.Provider == providerName {
+	// Check if already using this provider (changing the anthropic auth mode still applies)
+	authModeChange := providerName == anthropicProvider && authMode != "" &&
+		authMode != cfg.Providers[anthropicProvider].AuthMode
+	if cfg.Provider == providerName && !authModeChange {
 		if !quiet {
 			fmt.Printf("Already using %s provider\n", providerName)
 		}
 		return nil
 	}
 
+	if dryRun {
+		// Pending changes are reported through the exit code, not as an error
+		cmd.SilenceUsage = true
+		cmd.SilenceErrors = true
+		return previewSwitch(cfg, providerName, authMode, resolveSettingsPath(cmd))
+	}
+
+	return performSwitch(cmd.Context(), cfg, providerName, switchOptions{
+		authMode:     authMode,
+		modelsMode:   modelsMode,
+		settingsPath: resolveSettingsPath(cmd),
+		noHooks:      noHooks,
+		noSnapshot:   noSnapshot,
+		noInput:      noInput,
+		verbose:      verbose,
+		quiet:        quiet,
+	})
+}
+
+// switchOptions are the switch flags shared by the commands that change the
+// active provider
+type switchOptions struct {
+	authMode     string
+	modelsMode   string
+	settingsPath string
+	noHooks      bool
+	noSnapshot   bool
+	noInput      bool
+	verbose      bool
+	quiet        bool
+}
+
+// performSwitch makes providerName the active provider: it runs the switch
+// hooks, prompts for whatever the provider is missing (or fails with
+// noInput), saves the config and writes the Claude settings
+func performSwitch(ctx context.Context, cfg *config.Config, providerName string, opts switchOptions) error {
+	oldProvider := cfg.Provider
+	if !opts.noHooks {
+		if err := runSwitchHook(ctx, cfg.Hooks.PreSwitch, oldProvider, providerName); err != nil {
+			return fmt.Errorf("pre-switch %w; switch aborted", err)
+		}
+	}
+
 	// Configure provider if needed
 	if providerName != anthropicProvider {
-		if err := configureExternalProvider(cfg, providerName, verbose, quiet); err != nil {
+		if err := configureExternalProvider(cfg, providerName, opts.modelsMode, opts.noInput, opts.verbose, opts.quiet); err != nil {
 			return err
 		}
 	} else {
-		if err := configureAnthropicProvider(cfg, verbose, quiet); err != nil {
+		if err := configureAnthropicProvider(cfg, opts.authMode, opts.noInput, opts.verbose, opts.quiet); err != nil {
 			return err
 		}
 	}
 
 	// Switch provider
-	cfg.Provider = providerName
+	if err := cfg.SetActiveProvider(providerName); err != nil {
+		return err
+	}
 
 	// Save configuration
 	if err := config.SaveConfig(cfg); err != nil {
@@ -105,12 +207,18 @@ func runSwitch(cmd *cobra.Command, args []string) error {
 	}
 
 	// Generate Claude settings file
-	if err := generateClaudeSettings(cfg, quiet); err != nil {
+	if err := generateSwitchSettings(cfg, opts.settingsPath, oldProvider, opts.noSnapshot, opts.verbose, opts.quiet); err != nil {
 		return fmt.Errorf("failed to generate Claude settings: %w", err)
 	}
 
-	if !quiet {
-		displaySwitchSuccess(cfg, providerName, verbose)
+	if !opts.quiet {
+		displaySwitchSuccess(cfg, providerName, opts.verbose)
+	}
+
+	if !opts.noHooks {
+		if err := runSwitchHook(ctx, cfg.Hooks.PostSwitch, oldProvider, providerName); err != nil {
+			fmt.Printf("Warning: Post-switch %v\n", err)
+		}
 	}
 
 	return nil
@@ -118,7 +226,7 @@ func runSwitch(cmd *cobra.Command, args []string) error {
 
 func getProviderName(args []string, cfg *config.Config, verbose bool) (string, error) {
 	if len(args) > 0 {
-		return args[0], nil
+		return cfg.ResolveAlias(args[0])
 	}
 
 	return promptProviderSelection(cfg)
@@ -134,10 +242,19 @@ func getProviderDisplayInfo(providerName string, provider config.ProviderConfig)
 	if providerName == anthropicProvider {
 		displayName = anthropicName
 		statusText = statusOAuth
+		if provider.UsesAPIKey() {
+			statusText = statusAPI
+		}
 		return displayName, statusText
 	}
 
 	// External providers
+	statusText = statusAPI
+
+	if provider.DisplayName != "" {
+		return provider.DisplayName, statusText
+	}
+
 	switch providerName {
 	case claudeCodeProvider:
 		displayName = anthropicName
@@ -147,27 +264,57 @@ func getProviderDisplayInfo(providerName string, provider config.ProviderConfig)
 		displayName = providerName
 	}
 
-	statusText = statusAPI
-
 	return displayName, statusText
 }
 
-func configureExternalProvider(cfg *config.Config, providerName string, verbose, quiet bool) error {
+// configureExternalProvider prompts for whatever the provider is missing.
+// With noInput nothing is prompted for and a missing token or base URL is
+// an error.
+func configureExternalProvider(cfg *config.Config, providerName, modelsMode string, noInput, verbose, quiet bool) error {
 	provider := cfg.Providers[providerName]
 
-	// Configure token if needed
-	if err := configureToken(&provider, providerName); err != nil {
-		return err
+	// Configure token if needed; a key from the environment is never stored
+	if !cfg.NeedsAPIKey(providerName) {
+		if verbose && !quiet {
+			fmt.Println("No API key needed: Claude Code signs in with the subscription")
+		}
+	} else if resolved, fromEnv := cfg.ResolveProvider(providerName); !fromEnv {
+		if noInput && provider.Token == "" {
+			return fmt.Errorf("provider '%s' has no API key; set one with cflip config set-api-key %s", providerName, providerName)
+		}
+		if err := configureToken(&provider, providerName); err != nil {
+			return err
+		}
+		if verbose && !quiet {
+			fmt.Printf("Using API key %s from the config\n", config.Redact(provider.Token))
+		}
+	} else if verbose && !quiet {
+		fmt.Printf("Using API key %s from %s\n", config.Redact(resolved.Token), config.APIKeyEnvVar(providerName))
 	}
 
 	// Configure base URL if needed
+	if noInput && provider.BaseURL == "" {
+		return fmt.Errorf("provider '%s' has no base URL; set one with cflip config set-base-url %s <url>", providerName, providerName)
+	}
 	if err := configureBaseURL(&provider, providerName); err != nil {
 		return err
 	}
 
 	// Configure model mappings if requested
-	if err := configureModelMappings(&provider); err != nil {
-		return err
+	if !noInput {
+		if err := configureModelMappings(&provider); err != nil {
+			return err
+		}
+	}
+
+	// Never carry over another provider's model mappings
+	if len(provider.ModelMap) == 0 {
+		if modelsMode == modelsRequired {
+			return fmt.Errorf("provider '%s' has no model mappings; configure them or use --models %s", providerName, modelsClear)
+		}
+		if !quiet {
+			fmt.Printf("Note: %s has no model mappings, Claude Code default models will be used\n", providerName)
+		}
 	}
 
 	cfg.SetProviderConfig(providerName, provider)
@@ -229,8 +376,7 @@ func configureModelMappings(provider *config.ProviderConfig) error {
 	}
 
 	// Prompt for each category
-	categories := []string{"haiku", "sonnet", "opus"}
-	for _, category := range categories {
+	for _, category := range config.ModelCategories {
 		fmt.Printf("Enter model for %s category (optional): ", category)
 		input, _ := reader.ReadString('\n')
 		input = strings.TrimSpace(input)
@@ -264,22 +410,88 @@ func detectCurrentProvider(settings *ClaudeSettings) string {
 	return "anthropic"
 }
 
-func configureAnthropicProvider(cfg *config.Config, verbose, quiet bool) error {
-	// No configuration needed for Anthropic subscription plan
-	// Users can optionally configure an API key later if needed
+func configureAnthropicProvider(cfg *config.Config, authMode string, noInput, verbose, quiet bool) error {
+	provider := cfg.Providers[anthropicProvider]
+	resolved, _ := cfg.ResolveProvider(anthropicProvider)
+
+	if resolved.Token == "" {
+		// No API key: only the Claude Code subscription is available
+		if authMode == config.AuthModeAPI {
+			return fmt.Errorf("no API key configured for %s", anthropicProvider)
+		}
+		provider.AuthMode = ""
+	} else {
+		// Both an API key and the subscription are available, make the choice explicit
+		if authMode == "" && noInput {
+			authMode = provider.AuthMode
+			if authMode == "" {
+				authMode = config.AuthModeAPI
+			}
+		}
+		if authMode == "" {
+			mode, err := promptAuthMode(provider.AuthMode)
+			if err != nil {
+				return err
+			}
+			authMode = mode
+		}
+		provider.AuthMode = authMode
+	}
+
+	cfg.SetProviderConfig(anthropicProvider, provider)
+	resolved.AuthMode = provider.AuthMode
 
 	if !quiet && verbose {
-		fmt.Println("\nNote: Using Anthropic subscription plan")
-		fmt.Println("No API key required - will use your Claude Code subscription")
+		if resolved.UsesAPIKey() {
+			fmt.Printf("\nNote: Using Anthropic API key %s\n", config.Redact(resolved.Token))
+		} else {
+			fmt.Println("\nNote: Using Anthropic subscription plan")
+			fmt.Println("No API key required - will use your Claude Code subscription")
+		}
 	}
 
 	return nil
 }
 
-func generateClaudeSettings(cfg *config.Config, quiet bool) error {
-	// Claude settings path
-	homeDir, _ := os.UserHomeDir()
-	settingsPath := filepath.Join(homeDir, ".claude", "settings.json")
+// promptAuthMode asks which anthropic auth mode to activate, defaulting to the current one
+func promptAuthMode(current string) (string, error) {
+	if current == "" {
+		current = config.AuthModeAPI
+	}
+
+	fmt.Printf("Anthropic API key available. Use (a)pi key or (s)ubscription? [%s]: ", current)
+	reader := bufio.NewReader(os.Stdin)
+	input, _ := reader.ReadString('\n')
+	input = strings.TrimSpace(strings.ToLower(input))
+
+	switch input {
+	case "":
+		return current, nil
+	case "a", config.AuthModeAPI:
+		return config.AuthModeAPI, nil
+	case "s", config.AuthModeSubscription:
+		return config.AuthModeSubscription, nil
+	default:
+		return "", fmt.Errorf("invalid auth mode '%s'", input)
+	}
+}
+
+// generateClaudeSettings writes the settings for the active provider,
+// snapshotting the current ones first unless skipSnapshot is set or
+// snapshots are disabled in the config
+func generateClaudeSettings(cfg *config.Config, settingsPath string, skipSnapshot, verbose, quiet bool) error {
+	return generateSwitchSettings(cfg, settingsPath, cfg.Provider, skipSnapshot, verbose, quiet)
+}
+
+// generateSwitchSettings is generateClaudeSettings for writes that change
+// the active provider. fromProvider, the provider active before the write,
+// is recorded with the snapshot so rollback makes it active again.
+func generateSwitchSettings(cfg *config.Config, settingsPath, fromProvider string, skipSnapshot, verbose, quiet bool) error {
+	// Compute the env for the active provider before touching settings
+	env, err := cfg.GenerateSettingsPreview(cfg.Provider)
+	if err != nil {
+		return err
+	}
 
 	// Load current settings with all attributes
 	settings, err := LoadSettings(settingsPath)
@@ -287,77 +499,159 @@ func generateClaudeSettings(cfg *config.Config, quiet bool) error {
 		return fmt.Errorf("failed to load current settings: %w", err)
 	}
 
-	// Create snapshot before switching (always, even if user edited manually)
-	cflipDir := filepath.Dir(settingsPath)
-	snapshotsDir := filepath.Join(cflipDir, "snapshots")
+	switch {
+	case skipSnapshot:
+		if verbose && !quiet {
+			fmt.Println("Snapshot: skipped (--no-snapshot)")
+		}
+		clearRollbackPoint(snapshotsDirFor(settingsPath))
+	case cfg.Snapshots.Disabled:
+		if verbose && !quiet {
+			fmt.Println("Snapshot: skipped (snapshots disabled in config)")
+		}
+		clearRollbackPoint(snapshotsDirFor(settingsPath))
+	default:
+		takeSnapshot(settingsPath, settings, fromProvider, verbose, quiet)
+	}
+
+	applyProviderEnv(cfg, settings, env)
 
-	// Determine the current provider from existing settings
-	currentProvider := detectCurrentProvider(settings)
+	// Save settings preserving all other fields
+	return SaveSettings(settingsPath, settings)
+}
 
-	// Create snapshot with current provider name
-	if err := CreateSnapshot(settingsPath, snapshotsDir, currentProvider); err != nil {
+// takeSnapshot snapshots the current settings before they are rewritten,
+// records it with activeProvider as the rollback point and prunes old
+// snapshots. Failures are only reported.
+func takeSnapshot(settingsPath string, settings *ClaudeSettings, activeProvider string, verbose, quiet bool) {
+	snapshotsDir := snapshotsDirFor(settingsPath)
+
+	// Create snapshot with the provider detected from the current settings
+	provider := detectCurrentProvider(settings)
+	name, err := CreateSnapshot(settingsPath, snapshotsDir, provider)
+	switch {
+	case err != nil:
 		// Don't fail if snapshot fails, just log it
 		if !quiet {
 			fmt.Printf("Warning: Failed to create snapshot: %v\n", err)
 		}
+		clearRollbackPoint(snapshotsDir)
+	case verbose && !quiet && name == "":
+		fmt.Println("Snapshot: skipped (identical to the latest one)")
+	case verbose && !quiet:
+		fmt.Printf("Snapshot: created %s\n", name)
+	}
+
+	// Remember the snapshot so rollback can restore it
+	if err == nil {
+		if name == "" {
+			name, err = latestSnapshot(snapshotsDir, provider)
+		}
+		if err == nil {
+			err = recordRollbackPoint(snapshotsDir, name, activeProvider)
+		}
+		if err != nil && !quiet {
+			fmt.Printf("Warning: %v\n", err)
+		}
 	}
 
 	// Clean up old snapshots (keep last 5)
-	if err := CleanupOldSnapshots(snapshotsDir, 5); err != nil {
+	deleted, err := CleanupOldSnapshots(snapshotsDir, 5)
+	if err != nil {
 		fmt.Printf("Warning: Failed to cleanup old snapshots: %v\n", err)
 	}
+	if verbose && !quiet {
+		for _, name := range deleted {
+			fmt.Printf("Removed old snapshot: %s\n", name)
+		}
+	}
+}
+
+// applyProviderEnv replaces the env vars cflip owns with the provider's env
+func applyProviderEnv(cfg *config.Config, settings *ClaudeSettings, env map[string]string) {
+	if settings.Env == nil {
+		settings.Env = make(map[string]interface{})
+	}
 
 	// Clear existing Claude-related env vars
-	keysToDelete := []string{
-		"ANTHROPIC_AUTH_TOKEN",
-		"ANTHROPIC_BASE_URL",
-		"ANTHROPIC_DEFAULT_HAIKU_MODEL",
-		"ANTHROPIC_DEFAULT_SONNET_MODEL",
-		"ANTHROPIC_DEFAULT_OPUS_MODEL",
-	}
-	for _, key := range keysToDelete {
+	for _, key := range cfg.OwnedEnvKeys() {
 		delete(settings.Env, key)
 	}
 
-	// Configure based on provider
-	if cfg.Provider == anthropicProvider {
-		provider := cfg.Providers[anthropicProvider]
+	for key, value := range env {
+		settings.Env[key] = value
+	}
+}
 
-		// Only set API key if provided
-		if provider.Token != "" {
-			settings.Env["ANTHROPIC_AUTH_TOKEN"] = provider.Token
-		}
+// withProviderEnv returns a copy of settings with the provider's env
+// applied, leaving settings itself untouched
+func withProviderEnv(cfg *config.Config, settings *ClaudeSettings, env map[string]string) *ClaudeSettings {
+	updated := *settings
+	updated.Env = make(map[string]interface{}, len(settings.Env))
+	for key, value := range settings.Env {
+		updated.Env[key] = value
+	}
+	applyProviderEnv(cfg, &updated, env)
+	return &updated
+}
 
-		// Do NOT set ANTHROPIC_BASE_URL - use Claude Code default
-		// Do NOT set model mappings - use defaults
-	} else {
-		// External provider
-		provider := cfg.Providers[cfg.Provider]
+// previewSwitch prints the settings and config changes a switch would make.
+// Nothing is prompted for or written: the provider is previewed as currently
+// configured. It returns errChangesPending when the switch would change
+// anything, so scripts can use --dry-run as a check.
+func previewSwitch(cfg *config.Config, providerName, authMode, settingsPath string) error {
+	next := cfg.Clone()
+	if providerName == anthropicProvider && authMode != "" {
+		provider := next.Providers[anthropicProvider]
+		provider.AuthMode = authMode
+		next.SetProviderConfig(anthropicProvider, provider)
+	}
+	if next.Provider != providerName {
+		next.PreviousProvider = next.Provider
+	}
+	next.Provider = providerName
+
+	env, err := next.GenerateSettingsPreview(providerName)
+	if err != nil {
+		return err
+	}
 
-		// Set required fields
-		settings.Env["ANTHROPIC_AUTH_TOKEN"] = provider.Token
-		settings.Env["ANTHROPIC_BASE_URL"] = provider.BaseURL
+	current, err := LoadSettings(settingsPath)
+	if err != nil {
+		return fmt.Errorf("failed to load current settings: %w", err)
+	}
 
-		// Set model mappings if available
-		if len(provider.ModelMap) > 0 {
-			if haikuModel, exists := provider.ModelMap["haiku"]; exists {
-				settings.Env["ANTHROPIC_DEFAULT_HAIKU_MODEL"] = haikuModel
-			}
-			if sonnetModel, exists := provider.ModelMap["sonnet"]; exists {
-				settings.Env["ANTHROPIC_DEFAULT_SONNET_MODEL"] = sonnetModel
-			}
-			if opusModel, exists := provider.ModelMap["opus"]; exists {
-				settings.Env["ANTHROPIC_DEFAULT_OPUS_MODEL"] = opusModel
-			}
-		}
+	settingsChanges := diffSettings(current, withProviderEnv(next, current, env))
+	configChanges := diffSwitchConfig(cfg, next)
+	if len(settingsChanges) == 0 && len(configChanges) == 0 {
+		fmt.Printf("Dry run: switching to %s would not change anything\n", providerName)
+		return nil
 	}
 
-	// Save settings preserving all other fields
-	return SaveSettings(settingsPath, settings)
+	if len(settingsChanges) > 0 {
+		fmt.Printf("Dry run: switching to %s would change %s:\n", providerName, settingsPath)
+		printSettingsChanges(settingsChanges)
+	}
+	if len(configChanges) > 0 {
+		fmt.Printf("Dry run: switching to %s would change %s:\n", providerName, config.GetConfigPath())
+		printSettingsChanges(configChanges)
+	}
+	return errChangesPending
+}
+
+// diffSwitchConfig lists the config fields a switch changes
+func diffSwitchConfig(from, to *config.Config) []settingsChange {
+	changes := diffValue("provider", optionalString(from.Provider), optionalString(to.Provider))
+	changes = append(changes, diffValue("previous_provider",
+		optionalString(from.PreviousProvider), optionalString(to.PreviousProvider))...)
+	changes = append(changes, diffValue("providers.anthropic.auth_mode",
+		optionalString(from.Providers[anthropicProvider].AuthMode),
+		optionalString(to.Providers[anthropicProvider].AuthMode))...)
+	return changes
 }
 
 func displaySwitchSuccess(cfg *config.Config, providerName string, verbose bool) {
 	displayName, _ := getProviderDisplayInfo(providerName, cfg.Providers[providerName])
 
-	fmt.Printf("✓ Switched to %s\n", displayName)
+	fmt.Printf("%s Switched to %s\n", checkMark(), displayName)
 }
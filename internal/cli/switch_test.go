@@ -0,0 +1,777 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vanducng/cflip/internal/config"
+)
+
+// setupTestHome points HOME at a temp directory and seeds it with a config
+// containing a fully configured glm provider
+func setupTestHome(t *testing.T) string {
+	t.Helper()
+
+	home := isolateHome(t)
+
+	cfg := config.NewConfig()
+	cfg.SetProviderConfig(glmProvider, config.ProviderConfig{
+		Token:   "glm-test-token",
+		BaseURL: "https://api.z.ai/api/anthropic",
+	})
+	if err := config.SaveConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	return home
+}
+
+// isolateHome points HOME at a temp dir and clears the env vars that would
+// move the config or settings elsewhere
+func isolateHome(t *testing.T) string {
+	t.Helper()
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	for _, key := range []string{config.ConfigEnvVar, claudeConfigDirEnvVar, settingsPathEnvVar, "XDG_CONFIG_HOME", "XDG_STATE_HOME"} {
+		t.Setenv(key, "")
+	}
+	return home
+}
+
+// withEmptyStdin replaces stdin with a closed pipe so prompts read EOF
+func withEmptyStdin(t *testing.T) {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+
+	orig := os.Stdin
+	os.Stdin = r
+	t.Cleanup(func() {
+		os.Stdin = orig
+		r.Close()
+	})
+}
+
+func TestSwitchWithSettingsPath(t *testing.T) {
+	home := setupTestHome(t)
+	withEmptyStdin(t)
+
+	settingsPath := filepath.Join(t.TempDir(), "sandbox", "settings.json")
+	if err := switchCmd.Flags().Set("settings-path", settingsPath); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = switchCmd.Flags().Set("settings-path", "") })
+
+	if err := runSwitch(switchCmd, []string{glmProvider}); err != nil {
+		t.Fatalf("switch failed: %v", err)
+	}
+
+	settings, err := LoadSettings(settingsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := settings.Env["ANTHROPIC_BASE_URL"]; got != "https://api.z.ai/api/anthropic" {
+		t.Errorf("Expected base URL in overridden settings, got %v", got)
+	}
+
+	if _, err := os.Stat(filepath.Join(home, ".claude", "settings.json")); !os.IsNotExist(err) {
+		t.Error("Default settings file should not be touched")
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(settingsPath), "snapshots")); err != nil {
+		t.Errorf("Expected snapshots next to overridden settings: %v", err)
+	}
+}
+
+func TestSwitchWithSettingsPathEnv(t *testing.T) {
+	home := setupTestHome(t)
+	withEmptyStdin(t)
+
+	settingsPath := filepath.Join(t.TempDir(), "project", ".claude", "settings.json")
+	t.Setenv(settingsPathEnvVar, settingsPath)
+	t.Setenv(claudeConfigDirEnvVar, filepath.Join(home, "ignored"))
+
+	if err := runSwitch(switchCmd, []string{glmProvider}); err != nil {
+		t.Fatalf("switch failed: %v", err)
+	}
+
+	settings, err := LoadSettings(settingsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := settings.Env["ANTHROPIC_BASE_URL"]; got != "https://api.z.ai/api/anthropic" {
+		t.Errorf("Expected base URL in overridden settings, got %v", got)
+	}
+
+	for _, dir := range []string{filepath.Join(home, ".claude"), filepath.Join(home, "ignored")} {
+		if _, err := os.Stat(dir); !os.IsNotExist(err) {
+			t.Errorf("%s should not be touched", dir)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(settingsPath), "snapshots")); err != nil {
+		t.Errorf("Expected snapshots next to overridden settings: %v", err)
+	}
+
+	// The flag still wins over the env var
+	flagPath := filepath.Join(t.TempDir(), "settings.json")
+	if err := switchCmd.Flags().Set("settings-path", flagPath); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = switchCmd.Flags().Set("settings-path", "") })
+	if path, source := resolveSettingsPathSource(switchCmd); path != flagPath || source != "--settings-path" {
+		t.Errorf("Expected the flag to win, got %s from %s", path, source)
+	}
+}
+
+func TestAnthropicAuthModes(t *testing.T) {
+	tests := []struct {
+		name      string
+		authMode  string
+		wantToken bool
+	}{
+		{"api mode writes token", config.AuthModeAPI, true},
+		{"subscription mode omits token", config.AuthModeSubscription, false},
+		{"legacy empty mode writes token", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settingsPath := filepath.Join(t.TempDir(), "settings.json")
+
+			cfg := config.NewConfig()
+			cfg.SetProviderConfig(anthropicProvider, config.ProviderConfig{
+				Token:    "sk-ant-test",
+				AuthMode: tt.authMode,
+			})
+
+			if err := generateClaudeSettings(cfg, settingsPath, false, false, true); err != nil {
+				t.Fatal(err)
+			}
+
+			settings, err := LoadSettings(settingsPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, hasToken := settings.Env["ANTHROPIC_AUTH_TOKEN"]
+			if hasToken != tt.wantToken {
+				t.Errorf("Expected token present=%t, got %t", tt.wantToken, hasToken)
+			}
+		})
+	}
+}
+
+func TestSwitchAnthropicAuthModeChange(t *testing.T) {
+	setupTestHome(t)
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.SetProviderConfig(anthropicProvider, config.ProviderConfig{Token: "sk-ant-test", AuthMode: config.AuthModeAPI})
+	if err := config.SaveConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := switchCmd.Flags().Set("auth", config.AuthModeSubscription); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = switchCmd.Flags().Set("auth", "") })
+
+	if err := runSwitch(switchCmd, []string{anthropicProvider}); err != nil {
+		t.Fatalf("switch failed: %v", err)
+	}
+
+	cfg, err = config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider := cfg.Providers[anthropicProvider]
+	if provider.AuthMode != config.AuthModeSubscription {
+		t.Errorf("Expected auth mode to be recorded, got '%s'", provider.AuthMode)
+	}
+	if provider.Token != "sk-ant-test" {
+		t.Error("API key should be kept when switching to subscription mode")
+	}
+	if got := describeAuthMode(anthropicProvider, provider); got != "subscription (API key available)" {
+		t.Errorf("Unexpected status text: %s", got)
+	}
+}
+
+func TestSwitchToModellessProviderClearsModels(t *testing.T) {
+	setupTestHome(t)
+	withEmptyStdin(t)
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	glm := cfg.Providers[glmProvider]
+	glm.ModelMap = map[string]string{"haiku": "glm-4.5-air", "sonnet": "glm-4.6", "opus": "glm-4.6"}
+	cfg.SetProviderConfig(glmProvider, glm)
+	cfg.SetProviderConfig("bare", config.ProviderConfig{Token: "bare-token", BaseURL: "https://bare.example.com"})
+	cfg.Provider = glmProvider
+	if err := config.SaveConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	settingsPath := defaultSettingsPath()
+	if err := generateClaudeSettings(cfg, settingsPath, false, false, true); err != nil {
+		t.Fatal(err)
+	}
+
+	// The closed stdin answers the mapping prompt with empty categories
+	if err := runSwitch(switchCmd, []string{"bare"}); err != nil {
+		t.Fatalf("switch failed: %v", err)
+	}
+
+	settings, err := LoadSettings(settingsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{config.EnvHaikuModel, config.EnvSonnetModel, config.EnvOpusModel} {
+		if _, exists := settings.Env[key]; exists {
+			t.Errorf("Stale %s should have been removed", key)
+		}
+	}
+	if settings.Env[config.EnvBaseURL] != "https://bare.example.com" {
+		t.Errorf("Unexpected base URL: %v", settings.Env[config.EnvBaseURL])
+	}
+}
+
+func TestSwitchModelsRequired(t *testing.T) {
+	setupTestHome(t)
+	withEmptyStdin(t)
+
+	if err := switchCmd.Flags().Set("models", modelsRequired); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = switchCmd.Flags().Set("models", modelsClear) })
+
+	if err := runSwitch(switchCmd, []string{glmProvider}); err == nil {
+		t.Error("Expected switch to a modelless provider to fail with --models required")
+	}
+}
+
+func TestSwitchDryRunWritesNothing(t *testing.T) {
+	setupTestHome(t)
+	withEmptyStdin(t)
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	settingsPath := defaultSettingsPath()
+	if err := generateClaudeSettings(cfg, settingsPath, false, false, true); err != nil {
+		t.Fatal(err)
+	}
+
+	configBefore, err := os.ReadFile(config.GetConfigPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	settingsBefore, err := os.ReadFile(settingsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshotsBefore, err := ListSnapshots(snapshotsDirFor(settingsPath))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := switchCmd.Flags().Set("dry-run", "true"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = switchCmd.Flags().Set("dry-run", "false") })
+
+	out := captureStdout(t, func() error {
+		err := runSwitch(switchCmd, []string{glmProvider})
+		var exitErr *ExitError
+		if !errors.As(err, &exitErr) || exitErr.Code != 2 {
+			return fmt.Errorf("expected exit code 2 for pending changes, got %v", err)
+		}
+		return nil
+	})
+
+	if !strings.Contains(out, "+ env.ANTHROPIC_BASE_URL: https://api.z.ai/api/anthropic") {
+		t.Errorf("Expected the base URL in the dry-run diff, got:\n%s", out)
+	}
+	if !strings.Contains(out, "~ provider: anthropic → glm") {
+		t.Errorf("Expected the config change in the dry-run diff, got:\n%s", out)
+	}
+	if strings.Contains(out, "glm-test-token") {
+		t.Errorf("Dry-run output must not contain the API token:\n%s", out)
+	}
+
+	configAfter, err := os.ReadFile(config.GetConfigPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	settingsAfter, err := os.ReadFile(settingsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshotsAfter, err := ListSnapshots(snapshotsDirFor(settingsPath))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(configAfter) != string(configBefore) {
+		t.Error("Dry run changed the cflip config")
+	}
+	if string(settingsAfter) != string(settingsBefore) {
+		t.Error("Dry run changed the Claude settings")
+	}
+	if len(snapshotsAfter) != len(snapshotsBefore) {
+		t.Error("Dry run created a snapshot")
+	}
+}
+
+func TestSwitchPrevious(t *testing.T) {
+	setupTestHome(t)
+	withEmptyStdin(t)
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.SetProviderConfig("proxy", config.ProviderConfig{
+		Token:    "proxy-token",
+		BaseURL:  "https://proxy.example.com",
+		ModelMap: map[string]string{"sonnet": "proxy-sonnet"},
+	})
+	if err := config.SaveConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	// Nothing to go back to yet
+	if err := runSwitch(undoCmd, nil); err == nil {
+		t.Error("Expected an error when no previous provider is recorded")
+	}
+
+	for _, name := range []string{"proxy", glmProvider} {
+		if err := runSwitch(switchCmd, []string{name}); err != nil {
+			t.Fatalf("switch %s failed: %v", name, err)
+		}
+	}
+
+	cfg, err = config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Provider != glmProvider || cfg.PreviousProvider != "proxy" {
+		t.Fatalf("Expected glm with previous proxy, got %s with previous %s", cfg.Provider, cfg.PreviousProvider)
+	}
+
+	output := captureStdout(t, func() error {
+		return runSwitch(undoCmd, nil)
+	})
+	if strings.Contains(output, "Configure model mappings?") {
+		t.Errorf("Expected undo not to prompt, got:\n%s", output)
+	}
+
+	cfg, err = config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Provider != "proxy" || cfg.PreviousProvider != glmProvider {
+		t.Errorf("Expected proxy with previous glm, got %s with previous %s", cfg.Provider, cfg.PreviousProvider)
+	}
+
+	settings, err := LoadSettings(defaultSettingsPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := settings.Env[config.EnvSonnetModel]; got != "proxy-sonnet" {
+		t.Errorf("Expected the proxy model mapping to be restored, got %v", got)
+	}
+}
+
+func TestSwitchProviderEnvVars(t *testing.T) {
+	setupTestHome(t)
+	withEmptyStdin(t)
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider := cfg.Providers[glmProvider]
+	provider.EnvVars = map[string]string{"API_TIMEOUT_MS": "3000000"}
+	cfg.SetProviderConfig(glmProvider, provider)
+	if err := config.SaveConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runSwitch(switchCmd, []string{glmProvider}); err != nil {
+		t.Fatalf("switch to glm failed: %v", err)
+	}
+
+	settings, err := LoadSettings(defaultSettingsPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := settings.Env["API_TIMEOUT_MS"]; got != "3000000" {
+		t.Errorf("Expected API_TIMEOUT_MS from the glm provider, got %v", got)
+	}
+
+	if err := runSwitch(switchCmd, []string{anthropicProvider}); err != nil {
+		t.Fatalf("switch to anthropic failed: %v", err)
+	}
+
+	settings, err = LoadSettings(defaultSettingsPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := settings.Env["API_TIMEOUT_MS"]; ok {
+		t.Error("glm env vars should be removed when switching away")
+	}
+}
+
+func TestSwitchAPIKeyFromEnvironment(t *testing.T) {
+	setupTestHome(t)
+	withEmptyStdin(t)
+	t.Setenv("CFLIP_GLM_API_KEY", "glm-env-token")
+
+	if err := runSwitch(switchCmd, []string{glmProvider}); err != nil {
+		t.Fatalf("switch failed: %v", err)
+	}
+
+	settings, err := LoadSettings(defaultSettingsPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := settings.Env[config.EnvAuthToken]; got != "glm-env-token" {
+		t.Errorf("Expected the env API key to beat the stored key, got %v", got)
+	}
+
+	data, err := os.ReadFile(config.GetConfigPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "glm-env-token") {
+		t.Error("API key from the environment must not be written to config.toml")
+	}
+}
+
+func TestSwitchAPIKeyFromEnvironmentWithoutStoredKey(t *testing.T) {
+	setupTestHome(t)
+	withEmptyStdin(t)
+	t.Setenv("CFLIP_MY_PROXY_API_KEY", "proxy-env-token")
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.SetProviderConfig("my-proxy", config.ProviderConfig{BaseURL: "https://proxy.example.com"})
+	if err := config.SaveConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	// With stdin closed a token prompt would fail, so this also proves no prompt happens
+	if err := runSwitch(switchCmd, []string{"my-proxy"}); err != nil {
+		t.Fatalf("switch failed: %v", err)
+	}
+
+	cfg, err = config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token := cfg.Providers["my-proxy"].Token; token != "" {
+		t.Errorf("Config should stay keyless, got token %q", token)
+	}
+
+	settings, err := LoadSettings(defaultSettingsPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := settings.Env[config.EnvAuthToken]; got != "proxy-env-token" {
+		t.Errorf("Expected the env API key in settings, got %v", got)
+	}
+}
+
+// setSwitchHooks stores pre and post switch hooks in the test config
+func setSwitchHooks(t *testing.T, pre, post string) {
+	t.Helper()
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Hooks = config.Hooks{PreSwitch: pre, PostSwitch: post}
+	if err := config.SaveConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSwitchHooks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hooks run through sh in this test")
+	}
+
+	t.Run("hooks run with provider names", func(t *testing.T) {
+		home := setupTestHome(t)
+		withEmptyStdin(t)
+
+		pre := filepath.Join(home, "pre")
+		post := filepath.Join(home, "post")
+		setSwitchHooks(t,
+			`printf '%s %s' "$CFLIP_OLD_PROVIDER" "$CFLIP_NEW_PROVIDER" > `+pre,
+			`printf '%s %s' "$CFLIP_OLD_PROVIDER" "$CFLIP_NEW_PROVIDER" > `+post)
+
+		if err := runSwitch(switchCmd, []string{glmProvider}); err != nil {
+			t.Fatalf("switch failed: %v", err)
+		}
+
+		for _, marker := range []string{pre, post} {
+			data, err := os.ReadFile(marker)
+			if err != nil {
+				t.Fatalf("hook did not run: %v", err)
+			}
+			if got := string(data); got != "anthropic glm" {
+				t.Errorf("Expected hook env 'anthropic glm', got %q", got)
+			}
+		}
+	})
+
+	t.Run("failing pre-switch hook aborts", func(t *testing.T) {
+		setupTestHome(t)
+		withEmptyStdin(t)
+		setSwitchHooks(t, "exit 3", "")
+
+		if err := runSwitch(switchCmd, []string{glmProvider}); err == nil {
+			t.Fatal("Expected a failing pre-switch hook to abort the switch")
+		}
+
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cfg.Provider != anthropicProvider {
+			t.Errorf("Provider must not change when the pre-switch hook fails, got %s", cfg.Provider)
+		}
+	})
+
+	t.Run("failing post-switch hook only warns", func(t *testing.T) {
+		setupTestHome(t)
+		withEmptyStdin(t)
+		setSwitchHooks(t, "", "exit 3")
+
+		if err := runSwitch(switchCmd, []string{glmProvider}); err != nil {
+			t.Fatalf("A failing post-switch hook must not fail the switch: %v", err)
+		}
+
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cfg.Provider != glmProvider {
+			t.Errorf("Expected glm to stay active, got %s", cfg.Provider)
+		}
+	})
+
+	t.Run("no-hooks skips them", func(t *testing.T) {
+		home := setupTestHome(t)
+		withEmptyStdin(t)
+		resetFlags(t, switchCmd, "no-hooks")
+
+		marker := filepath.Join(home, "pre")
+		setSwitchHooks(t, "touch "+marker, "")
+		_ = switchCmd.Flags().Set("no-hooks", "true")
+
+		if err := runSwitch(switchCmd, []string{glmProvider}); err != nil {
+			t.Fatalf("switch failed: %v", err)
+		}
+		if _, err := os.Stat(marker); !os.IsNotExist(err) {
+			t.Error("Hooks must not run with --no-hooks")
+		}
+	})
+}
+
+// withOpenStdin replaces stdin with a pipe that is never written to or
+// closed, so any prompt blocks
+func withOpenStdin(t *testing.T) {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	orig := os.Stdin
+	os.Stdin = r
+	t.Cleanup(func() {
+		os.Stdin = orig
+		w.Close()
+		r.Close()
+	})
+}
+
+func TestSwitchYesNeverPrompts(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		setup   func(t *testing.T)
+		wantErr string
+	}{
+		{name: "configured provider", args: []string{glmProvider}},
+		{name: "missing token", args: []string{"newprov"}, wantErr: "has no API key",
+			setup: func(t *testing.T) {
+				cfg, _ := config.LoadConfig()
+				cfg.SetProviderConfig("newprov", config.ProviderConfig{BaseURL: "https://new.example.com"})
+				_ = config.SaveConfig(cfg)
+			}},
+		{name: "missing base URL", args: []string{"newprov"}, wantErr: "has no base URL",
+			setup: func(t *testing.T) {
+				cfg, _ := config.LoadConfig()
+				cfg.SetProviderConfig("newprov", config.ProviderConfig{Token: "new-token"})
+				_ = config.SaveConfig(cfg)
+			}},
+		{name: "anthropic with key", args: []string{anthropicProvider},
+			setup: func(t *testing.T) {
+				cfg, _ := config.LoadConfig()
+				cfg.Provider = glmProvider
+				cfg.SetProviderConfig(anthropicProvider, config.ProviderConfig{Token: "sk-ant-test"})
+				_ = config.SaveConfig(cfg)
+			}},
+		{name: "no provider", wantErr: "needs a provider name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupTestHome(t)
+			withOpenStdin(t)
+			resetFlags(t, switchCmd, "yes")
+			_ = switchCmd.Flags().Set("yes", "true")
+			if tt.setup != nil {
+				tt.setup(t)
+			}
+
+			type result struct {
+				out string
+				err error
+			}
+			done := make(chan result, 1)
+			go func() {
+				var runErr error
+				out := captureStdout(t, func() error {
+					runErr = runSwitch(switchCmd, tt.args)
+					return nil
+				})
+				done <- result{out, runErr}
+			}()
+
+			var out string
+			var err error
+			select {
+			case r := <-done:
+				out, err = r.out, r.err
+			case <-time.After(5 * time.Second):
+				t.Fatal("switch --yes blocked waiting for input")
+			}
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Expected an error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("switch --yes failed: %v", err)
+			}
+			if strings.Contains(out, "?") || strings.Contains(out, "Enter ") {
+				t.Errorf("Expected no prompts, got:\n%s", out)
+			}
+		})
+	}
+}
+
+func TestGenerateSettingsSkipsSnapshot(t *testing.T) {
+	tests := []struct {
+		name     string
+		flag     bool
+		disabled bool
+		want     string
+	}{
+		{name: "default", want: "Snapshot: created snapshot-"},
+		{name: "flag", flag: true, want: "Snapshot: skipped (--no-snapshot)"},
+		{name: "config", disabled: true, want: "Snapshot: skipped (snapshots disabled in config)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupTestHome(t)
+			settingsPath := filepath.Join(t.TempDir(), "settings.json")
+			writeTestSettings(t, settingsPath, map[string]interface{}{"KEEP": "1"})
+
+			cfg, err := config.LoadConfig()
+			if err != nil {
+				t.Fatal(err)
+			}
+			cfg.Provider = glmProvider
+			cfg.Snapshots.Disabled = tt.disabled
+
+			out := captureStdout(t, func() error {
+				return generateClaudeSettings(cfg, settingsPath, tt.flag, true, false)
+			})
+			if !strings.Contains(out, tt.want) {
+				t.Errorf("Expected %q in:\n%s", tt.want, out)
+			}
+
+			snapshots, err := ListSnapshots(snapshotsDirFor(settingsPath))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if skipped := tt.flag || tt.disabled; skipped != (len(snapshots) == 0) {
+				t.Errorf("Expected skipped=%t, got snapshots %v", skipped, snapshots)
+			}
+		})
+	}
+}
+
+func TestSwitchVerboseRedactsToken(t *testing.T) {
+	setupTestHome(t)
+	withEmptyStdin(t)
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	settingsPath := filepath.Join(t.TempDir(), "settings.json")
+
+	out := captureStdout(t, func() error {
+		if err := configureExternalProvider(cfg, glmProvider, modelsClear, true, true, false); err != nil {
+			return err
+		}
+		if err := cfg.SetActiveProvider(glmProvider); err != nil {
+			return err
+		}
+		if err := generateClaudeSettings(cfg, settingsPath, false, true, false); err != nil {
+			return err
+		}
+		// Previewing a switch away lists the token being removed
+		if err := previewSwitch(cfg, anthropicProvider, "", settingsPath); err != errChangesPending {
+			return err
+		}
+		return nil
+	})
+
+	if strings.Contains(out, "glm-test-token") {
+		t.Errorf("Verbose output must not contain the API token:\n%s", out)
+	}
+	for _, want := range []string{
+		"Using API key " + config.Redact("glm-test-token"),
+		"- env.ANTHROPIC_AUTH_TOKEN: " + config.Redact("glm-test-token"),
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in the verbose output:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,314 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/vanducng/cflip/internal/config"
+)
+
+// anthropicBaseURL is the endpoint Claude Code uses when no base URL is set
+const anthropicBaseURL = "https://api.anthropic.com"
+
+// anthropicAPIVersion is sent with every connection test request
+const anthropicAPIVersion = "2023-06-01"
+
+// defaultConnectionTimeout bounds a connection test unless --timeout is given
+const defaultConnectionTimeout = 10 * time.Second
+
+// testConnectionCmd checks that a provider accepts its credentials
+var testConnectionCmd = &cobra.Command{
+	Use:   "test [provider]",
+	Short: "Check that a provider accepts its API key",
+	Long: `Send an authenticated request to a provider's models endpoint and report
+whether the key is accepted. A provider that cannot be reached or does not
+answer within --timeout is reported as a network error, not a bad key. The
+active provider is tested unless one is named; --all tests every configured
+provider and prints a summary.
+
+The key stored in the config is used, or CFLIP_<PROVIDER>_API_KEY when set.
+Providers without a key, such as anthropic with a Claude subscription, are
+skipped. When a provider accepts its key, the time is saved to the config
+and shown by cflip status as "Last validated".`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeProviderNames,
+	SilenceUsage:      true,
+	RunE:              runTestConnection,
+}
+
+func init() {
+	testConnectionCmd.Flags().Bool("all", false, "Test every configured provider")
+	testConnectionCmd.Flags().Duration("timeout", defaultConnectionTimeout, "Give up on a provider after this long")
+}
+
+// NewTestCmd exports the test command
+func NewTestCmd() *cobra.Command {
+	return testConnectionCmd
+}
+
+// connectionResult is the outcome of testing one provider
+type connectionResult struct {
+	Provider string
+	OK       bool
+	Skipped  bool
+	Status   int
+	Reason   string
+	Elapsed  time.Duration
+}
+
+func runTestConnection(cmd *cobra.Command, args []string) error {
+	all, _ := cmd.Flags().GetBool("all")
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+
+	if all && len(args) > 0 {
+		return fmt.Errorf("--all cannot be combined with a provider name")
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	var names []string
+	switch {
+	case all:
+		names = sortedProviderNames(cfg)
+	case len(args) == 1:
+		name, err := cfg.ResolveAlias(args[0])
+		if err != nil {
+			return err
+		}
+		names = []string{name}
+	default:
+		if _, err := cfg.GetActiveProvider(); err != nil {
+			return err
+		}
+		names = []string{cfg.Provider}
+	}
+
+	client := &http.Client{Timeout: timeout}
+	results := make([]connectionResult, 0, len(names))
+	for _, name := range names {
+		results = append(results, testProviderConnection(cmd.Context(), client, cfg, name))
+	}
+
+	if err := recordValidations(results); err != nil {
+		return err
+	}
+
+	if all {
+		if err := printConnectionTable(results); err != nil {
+			return err
+		}
+	} else {
+		printConnectionResult(results[0])
+	}
+
+	failed := 0
+	for _, result := range results {
+		if !result.OK && !result.Skipped {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d provider(s) failed the connection test", failed)
+	}
+	return nil
+}
+
+// recordValidations saves when each provider that passed was validated. The
+// config is locked and reloaded only for the write, so a switch running
+// during the requests is neither blocked nor overwritten.
+func recordValidations(results []connectionResult) error {
+	var passed []string
+	for _, result := range results {
+		if result.OK {
+			passed = append(passed, result.Provider)
+		}
+	}
+	if len(passed) == 0 {
+		return nil
+	}
+
+	unlock, err := config.LockConfig(config.LockTimeout)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	now := time.Now()
+	for _, name := range passed {
+		// Skip providers removed while the requests ran
+		if _, exists := cfg.Providers[name]; !exists {
+			continue
+		}
+		if err := cfg.MarkValidated(name, now); err != nil {
+			return err
+		}
+	}
+
+	if err := config.SaveConfig(cfg); err != nil {
+		return fmt.Errorf("failed to save configuration: %w", err)
+	}
+	return nil
+}
+
+// testProviderConnection lists the provider's models with its key and
+// classifies the response
+func testProviderConnection(ctx context.Context, client *http.Client, cfg *config.Config, name string) connectionResult {
+	provider, _ := cfg.ResolveProvider(name)
+	return testProviderKey(ctx, client, name, provider, cfg.IsExternal(name))
+}
+
+// testProviderKey is testProviderConnection for a provider configuration
+// that is not saved yet, such as one with a new key
+func testProviderKey(ctx context.Context, client *http.Client, name string, provider config.ProviderConfig, external bool) connectionResult {
+	result := connectionResult{Provider: name}
+
+	baseURL := provider.BaseURL
+	if external && provider.AuthMode == config.AuthModeSubscription {
+		result.Skipped = true
+		result.Reason = "no API key; Claude Code signs in with the subscription"
+		return result
+	}
+	if !external {
+		if !provider.UsesAPIKey() {
+			result.Skipped = true
+			result.Reason = "no API key; Claude Code signs in with the subscription"
+			return result
+		}
+		baseURL = anthropicBaseURL
+	}
+	switch {
+	case provider.Token == "":
+		result.Skipped = true
+		result.Reason = "no API key configured"
+		return result
+	case baseURL == "":
+		result.Reason = "no base URL configured"
+		return result
+	}
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(baseURL, "/")+"/v1/models", nil)
+	if err != nil {
+		result.Reason = fmt.Sprintf("invalid base URL: %v", err)
+		return result
+	}
+	if provider.TokenEnvKey() == config.EnvAPIKey {
+		req.Header.Set("x-api-key", provider.Token)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+provider.Token)
+	}
+	req.Header.Set("anthropic-version", anthropicAPIVersion)
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	result.Elapsed = time.Since(start)
+	if err != nil {
+		result.Reason = describeNetworkError(err, client.Timeout)
+		return result
+	}
+	resp.Body.Close()
+
+	result.Status = resp.StatusCode
+	result.OK, result.Reason = describeConnectionStatus(resp.StatusCode)
+	return result
+}
+
+// describeNetworkError explains a request that got no response, which says
+// nothing about the key
+func describeNetworkError(err error, timeout time.Duration) string {
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return fmt.Sprintf("network error: no response within %s", timeout)
+	}
+	// Drop the method and URL that *url.Error adds
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		err = urlErr.Err
+	}
+	return fmt.Sprintf("network error: %v", err)
+}
+
+// describeConnectionStatus explains what an HTTP status says about the key
+func describeConnectionStatus(status int) (bool, string) {
+	switch {
+	case status >= 200 && status < 300:
+		return true, "key accepted"
+	case status == http.StatusUnauthorized:
+		return false, "key rejected; check the token"
+	case status == http.StatusForbidden:
+		return false, "key not allowed to use this endpoint"
+	case status == http.StatusNotFound:
+		return false, "endpoint not found; check the base URL"
+	case status == http.StatusTooManyRequests:
+		return false, "rate limited; try again later"
+	case status >= 500:
+		return false, "provider error; try again later"
+	default:
+		return false, "unexpected response"
+	}
+}
+
+// printConnectionResult prints the result of testing a single provider
+func printConnectionResult(result connectionResult) {
+	switch {
+	case result.Skipped:
+		fmt.Printf("%s %s: skipped, %s\n", warningMark(), result.Provider, result.Reason)
+	case result.OK:
+		fmt.Printf("%s %s: %s (%s, %s)\n", checkMark(), result.Provider, result.Reason,
+			statusText(result.Status), result.Elapsed.Round(time.Millisecond))
+	case result.Status != 0:
+		fmt.Printf("%s %s: %s (%s)\n", crossMark(), result.Provider, result.Reason, statusText(result.Status))
+	default:
+		fmt.Printf("%s %s: %s\n", crossMark(), result.Provider, result.Reason)
+	}
+}
+
+// printConnectionTable prints a summary row per provider
+func printConnectionTable(results []connectionResult) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PROVIDER\tRESULT\tSTATUS\tTIME\tDETAIL")
+
+	for _, result := range results {
+		outcome := "fail"
+		switch {
+		case result.Skipped:
+			outcome = "skip"
+		case result.OK:
+			outcome = "ok"
+		}
+
+		status, elapsed := "-", "-"
+		if result.Status != 0 {
+			status = fmt.Sprintf("%d", result.Status)
+			elapsed = result.Elapsed.Round(time.Millisecond).String()
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", result.Provider, outcome, status, elapsed, result.Reason)
+	}
+
+	return w.Flush()
+}
+
+// statusText formats an HTTP status as "401 Unauthorized"
+func statusText(status int) string {
+	return fmt.Sprintf("%d %s", status, http.StatusText(status))
+}
@@ -0,0 +1,238 @@
+package cli
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vanducng/cflip/internal/config"
+)
+
+// newProviderServer serves status for /v1/models and records the auth headers
+func newProviderServer(t *testing.T, status int, headers http.Header) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/models" {
+			http.NotFound(w, r)
+			return
+		}
+		for _, key := range []string{"Authorization", "X-Api-Key"} {
+			headers.Set(key, r.Header.Get(key))
+		}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestProviderConnectionStatuses(t *testing.T) {
+	tests := []struct {
+		status int
+		ok     bool
+		reason string
+	}{
+		{status: http.StatusOK, ok: true, reason: "key accepted"},
+		{status: http.StatusUnauthorized, reason: "key rejected"},
+		{status: http.StatusForbidden, reason: "not allowed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			headers := http.Header{}
+			server := newProviderServer(t, tt.status, headers)
+
+			cfg := config.NewConfig()
+			cfg.SetProviderConfig(glmProvider, config.ProviderConfig{Token: "glm-test-token", BaseURL: server.URL + "/"})
+
+			result := testProviderConnection(context.Background(), server.Client(), cfg, glmProvider)
+			if result.OK != tt.ok || result.Status != tt.status {
+				t.Errorf("Expected ok=%t status=%d, got %+v", tt.ok, tt.status, result)
+			}
+			if !strings.Contains(result.Reason, tt.reason) {
+				t.Errorf("Expected reason containing %q, got %q", tt.reason, result.Reason)
+			}
+			if got := headers.Get("Authorization"); got != "Bearer glm-test-token" {
+				t.Errorf("Expected a bearer token, got %q", got)
+			}
+		})
+	}
+}
+
+func TestProviderConnectionNetworkErrors(t *testing.T) {
+	// A closed server refuses the connection
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	t.Cleanup(slow.Close)
+
+	tests := map[string]struct {
+		url    string
+		reason string
+	}{
+		"refused": {url: closed.URL, reason: "network error: dial tcp"},
+		"timeout": {url: slow.URL, reason: "network error: no response within 50ms"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg := config.NewConfig()
+			cfg.SetProviderConfig(glmProvider, config.ProviderConfig{Token: "glm-test-token", BaseURL: tt.url})
+
+			client := &http.Client{Timeout: 50 * time.Millisecond}
+			result := testProviderConnection(context.Background(), client, cfg, glmProvider)
+			if result.OK || result.Status != 0 {
+				t.Errorf("Expected a failure without a status, got %+v", result)
+			}
+			if !strings.HasPrefix(result.Reason, tt.reason) {
+				t.Errorf("Expected reason starting with %q, got %q", tt.reason, result.Reason)
+			}
+		})
+	}
+}
+
+func TestProviderConnectionUsesAPIKeyHeader(t *testing.T) {
+	headers := http.Header{}
+	server := newProviderServer(t, http.StatusOK, headers)
+
+	cfg := config.NewConfig()
+	cfg.SetProviderConfig(glmProvider, config.ProviderConfig{
+		Token: "glm-test-token", BaseURL: server.URL, AuthHeader: config.AuthHeaderAPIKey,
+	})
+
+	if result := testProviderConnection(context.Background(), server.Client(), cfg, glmProvider); !result.OK {
+		t.Fatalf("Expected the key to be accepted, got %+v", result)
+	}
+	if got := headers.Get("X-Api-Key"); got != "glm-test-token" {
+		t.Errorf("Expected the token in x-api-key, got %q", got)
+	}
+	if got := headers.Get("Authorization"); got != "" {
+		t.Errorf("Expected no Authorization header, got %q", got)
+	}
+}
+
+func TestTestAllPrintsSummary(t *testing.T) {
+	setupTestHome(t)
+	resetFlags(t, testConnectionCmd, "all")
+
+	server := newProviderServer(t, http.StatusOK, http.Header{})
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider := cfg.Providers[glmProvider]
+	provider.BaseURL = server.URL
+	cfg.SetProviderConfig(glmProvider, provider)
+	if err := config.SaveConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	_ = testConnectionCmd.Flags().Set("all", "true")
+	out := captureStdout(t, func() error { return runTestConnection(testConnectionCmd, nil) })
+
+	rows := map[string][]string{}
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		if fields := strings.Fields(line); len(fields) > 0 {
+			rows[fields[0]] = fields
+		}
+	}
+	want := map[string][]string{
+		"PROVIDER":        {"RESULT", "STATUS", "TIME", "DETAIL"},
+		anthropicProvider: {"skip", "-", "-"},
+		glmProvider:       {"ok", "200"},
+	}
+	for provider, fields := range want {
+		row, ok := rows[provider]
+		if !ok || len(row) < len(fields)+1 || !slices.Equal(row[1:len(fields)+1], fields) {
+			t.Errorf("Expected a %s row starting with %v, got %v in:\n%s", provider, fields, row, out)
+		}
+	}
+}
+
+func TestTestRecordsLastValidated(t *testing.T) {
+	setupTestHome(t)
+	resetFlags(t, testConnectionCmd, "all")
+
+	server := newProviderServer(t, http.StatusOK, http.Header{})
+	rejecting := newProviderServer(t, http.StatusUnauthorized, http.Header{})
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider := cfg.Providers[glmProvider]
+	provider.BaseURL = server.URL
+	cfg.SetProviderConfig(glmProvider, provider)
+	cfg.SetProviderConfig("bad", config.ProviderConfig{Token: "bad-token", BaseURL: rejecting.URL})
+	if err := config.SaveConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	before := time.Now().Add(-time.Second)
+	captureStdout(t, func() error { return runTestConnection(testConnectionCmd, []string{glmProvider}) })
+	if err := runTestConnection(testConnectionCmd, []string{"bad"}); err == nil {
+		t.Error("Expected the rejected key to fail")
+	}
+
+	cfg, err = config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.Providers[glmProvider].LastValidated; got.Before(before) {
+		t.Errorf("Expected glm to be stamped as validated, got %v", got)
+	}
+	if got := cfg.Providers["bad"].LastValidated; !got.IsZero() {
+		t.Errorf("Expected a rejected key to leave LastValidated unset, got %v", got)
+	}
+
+	cfg.Provider = glmProvider
+	if got := buildStatusOutput(cfg).LastValidated; got.Before(before) {
+		t.Errorf("Expected status to report when glm was validated, got %v", got)
+	}
+}
+
+func TestTestKeepsConfigChangesMadeDuringRequests(t *testing.T) {
+	setupTestHome(t)
+
+	// A switch lands while the request is in flight
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		unlock, err := config.LockConfig(0)
+		if err != nil {
+			t.Errorf("Expected the config lock to be free during requests: %v", err)
+			return
+		}
+		defer unlock()
+		cfg, _ := config.LoadConfig()
+		cfg.Provider = glmProvider
+		_ = config.SaveConfig(cfg)
+	}))
+	t.Cleanup(server.Close)
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider := cfg.Providers[glmProvider]
+	provider.BaseURL = server.URL
+	cfg.SetProviderConfig(glmProvider, provider)
+	if err := config.SaveConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	captureStdout(t, func() error { return runTestConnection(testConnectionCmd, []string{glmProvider}) })
+
+	cfg, err = config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Provider != glmProvider {
+		t.Errorf("Expected the concurrent switch to be kept, got provider %s", cfg.Provider)
+	}
+	if cfg.Providers[glmProvider].LastValidated.IsZero() {
+		t.Error("Expected glm to be stamped as validated")
+	}
+}
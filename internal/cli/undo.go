@@ -0,0 +1,38 @@
+package cli
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// undoCmd switches back to the previously active provider
+var undoCmd = &cobra.Command{
+	Use:   "undo",
+	Short: "Switch back to the previous provider",
+	Long: `Switch back to the provider that was active before the last switch and
+regenerate Claude settings. Same as cflip switch --previous --yes: undo never
+prompts, it uses the previous provider as configured and fails if its token
+or base URL is missing.`,
+	Args: cobra.NoArgs,
+	RunE: runSwitch,
+}
+
+func init() {
+	undoCmd.Flags().String("settings-path", "", "Claude settings file to write (default ~/.claude/settings.json)")
+	undoCmd.Flags().Bool("no-hooks", false, "Do not run the pre and post switch hooks")
+	undoCmd.Flags().Bool("no-snapshot", false, "Do not snapshot the current settings before writing")
+
+	// runSwitch reads --previous; undo always sets it
+	undoCmd.Flags().Bool("previous", true, "")
+	_ = undoCmd.Flags().MarkHidden("previous")
+
+	// runSwitch reads --yes and --no-input; undo never prompts, so --yes is on.
+	// Both are accepted so scripts can pass the same flags as to switch.
+	undoCmd.Flags().BoolP("yes", "y", true, "")
+	undoCmd.Flags().Bool("no-input", false, "Same as --yes")
+	_ = undoCmd.Flags().MarkHidden("yes")
+}
+
+// NewUndoCmd exports the undo command
+func NewUndoCmd() *cobra.Command {
+	return undoCmd
+}
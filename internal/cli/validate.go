@@ -0,0 +1,249 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"github.com/vanducng/cflip/internal/config"
+)
+
+const (
+	severityError   = "error"
+	severityWarning = "warning"
+)
+
+// validateCmd represents the validate command
+var validateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check the configuration and Claude settings for problems",
+	Long: `Validate the cflip config file and check that the env block in
+~/.claude/settings.json matches the active provider.
+
+Each check is printed as a checklist item. The active provider must have an
+API key and every model mapping must name a known category and a model.
+
+Exits non-zero when an error-level issue is found; warnings do not affect
+the exit code.`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         runValidate,
+}
+
+func init() {
+	validateCmd.Flags().BoolP("json", "j", false, "Output in JSON format")
+	validateCmd.Flags().String("settings-path", "", "Claude settings file to check (default ~/.claude/settings.json)")
+}
+
+// NewValidateCmd exports the validate command
+func NewValidateCmd() *cobra.Command {
+	return validateCmd
+}
+
+// validationIssue is a single problem reported by validate
+type validationIssue struct {
+	Severity string `json:"severity"`
+	Check    string `json:"check"`
+	Message  string `json:"message"`
+	Fix      string `json:"fix,omitempty"`
+}
+
+// validationReport is the JSON document printed by validate --json
+type validationReport struct {
+	Valid  bool              `json:"valid"`
+	Checks []string          `json:"checks"`
+	Issues []validationIssue `json:"issues"`
+}
+
+func runValidate(cmd *cobra.Command, args []string) error {
+	jsonOutput, _ := cmd.Flags().GetBool("json")
+
+	checks, issues := collectValidationIssues(resolveSettingsPath(cmd))
+	errorCount := 0
+	for _, issue := range issues {
+		if issue.Severity == severityError {
+			errorCount++
+		}
+	}
+
+	if jsonOutput {
+		report := validationReport{Valid: errorCount == 0, Checks: checks, Issues: issues}
+		if report.Issues == nil {
+			report.Issues = []validationIssue{}
+		}
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal validation report: %w", err)
+		}
+		fmt.Println(string(data))
+	} else {
+		printValidationIssues(checks, issues)
+	}
+
+	if errorCount > 0 {
+		return fmt.Errorf("validation failed with %d error(s)", errorCount)
+	}
+	return nil
+}
+
+// printValidationIssues prints one checklist line per check, followed by its issues
+func printValidationIssues(checks []string, issues []validationIssue) {
+	for _, check := range checks {
+		var found []validationIssue
+		for _, issue := range issues {
+			if issue.Check == check {
+				found = append(found, issue)
+			}
+		}
+
+		if len(found) == 0 {
+			fmt.Printf("%s %s\n", checkMark(), check)
+			continue
+		}
+
+		for _, issue := range found {
+			marker := crossMark()
+			if issue.Severity == severityWarning {
+				marker = warningMark()
+			}
+			fmt.Printf("%s [%s] %s: %s\n", marker, issue.Severity, issue.Check, issue.Message)
+			if issue.Fix != "" {
+				fmt.Printf("    fix: %s\n", issue.Fix)
+			}
+		}
+	}
+
+	if len(issues) == 0 {
+		fmt.Printf("%s Configuration is valid\n", checkMark())
+	}
+}
+
+// collectValidationIssues checks the cflip config and the Claude settings
+// file. It returns the names of the checks that ran, in order, and the issues found.
+func collectValidationIssues(settingsPath string) ([]string, []validationIssue) {
+	var issues []validationIssue
+	checks := []string{"config"}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return checks, append(issues, validationIssue{
+			Severity: severityError,
+			Check:    "config",
+			Message:  err.Error(),
+			Fix:      fmt.Sprintf("fix the TOML syntax in %s", config.GetConfigPath()),
+		})
+	}
+
+	checks = append(checks, "active provider")
+	if _, exists := cfg.Providers[cfg.Provider]; !exists {
+		issues = append(issues, validationIssue{
+			Severity: severityError,
+			Check:    "active provider",
+			Message:  fmt.Sprintf("active provider '%s' is not configured", cfg.Provider),
+			Fix:      "run cflip switch to pick a configured provider",
+		})
+	}
+
+	for _, name := range sortedProviderNames(cfg) {
+		checks = append(checks, "provider "+name)
+		if err := cfg.ValidateProvider(name); err != nil {
+			issues = append(issues, validationIssue{
+				Severity: severityError,
+				Check:    "provider " + name,
+				Message:  err.Error(),
+				Fix:      "correct the provider with cflip config add-provider " + name + " --force",
+			})
+		}
+
+		provider, _ := cfg.ResolveProvider(name)
+		if cfg.NeedsAPIKey(name) && provider.Token == "" {
+			issue := validationIssue{
+				Severity: severityWarning,
+				Check:    "provider " + name,
+				Message:  "no API key configured",
+				Fix:      "run cflip config set-api-key " + name,
+			}
+			if name == cfg.Provider {
+				issue.Severity = severityError
+			}
+			issues = append(issues, issue)
+		}
+
+		if !cfg.IsExternal(name) && len(provider.ModelMap) > 0 {
+			issues = append(issues, validationIssue{
+				Severity: severityWarning,
+				Check:    "provider " + name,
+				Message:  "model mappings are ignored for the Anthropic provider",
+				Fix:      "remove the model_map section from " + config.GetConfigPath(),
+			})
+		}
+	}
+
+	checks = append(checks, "settings")
+	return checks, append(issues, checkSettingsConsistency(cfg, settingsPath)...)
+}
+
+// checkSettingsConsistency compares the managed env vars in Claude settings with the active provider
+func checkSettingsConsistency(cfg *config.Config, settingsPath string) []validationIssue {
+	expected, err := cfg.GenerateSettingsPreview(cfg.Provider)
+	if err != nil {
+		// Already reported as an active provider issue
+		return nil
+	}
+
+	if _, err := os.Stat(settingsPath); os.IsNotExist(err) {
+		return []validationIssue{{
+			Severity: severityWarning,
+			Check:    "settings",
+			Message:  fmt.Sprintf("%s does not exist", settingsPath),
+			Fix:      "run cflip switch to generate it",
+		}}
+	}
+
+	settings, err := LoadSettings(settingsPath)
+	if err != nil {
+		return []validationIssue{{
+			Severity: severityError,
+			Check:    "settings",
+			Message:  err.Error(),
+			Fix:      fmt.Sprintf("fix the JSON in %s (cflip edit)", settingsPath),
+		}}
+	}
+
+	keys := cfg.OwnedEnvKeys()
+	sort.Strings(keys)
+
+	var issues []validationIssue
+	for _, key := range keys {
+		want, wantSet := expected[key]
+		got, gotSet := settings.Env[key]
+		gotStr := fmt.Sprintf("%v", got)
+
+		var message string
+		switch {
+		case wantSet && !gotSet:
+			message = fmt.Sprintf("%s is missing", key)
+		case !wantSet && gotSet:
+			message = fmt.Sprintf("%s is set but %s does not use it", key, cfg.Provider)
+		case wantSet && gotStr != want:
+			if key == config.EnvAuthToken || key == config.EnvAPIKey {
+				message = fmt.Sprintf("%s does not match the configured API key", key)
+			} else {
+				message = fmt.Sprintf("%s is %q, expected %q", key, gotStr, want)
+			}
+		default:
+			continue
+		}
+
+		issues = append(issues, validationIssue{
+			Severity: severityError,
+			Check:    "settings",
+			Message:  message,
+			Fix:      fmt.Sprintf("update the env block in %s (cflip edit)", settingsPath),
+		})
+	}
+
+	return issues
+}
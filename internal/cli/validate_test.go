@@ -0,0 +1,130 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/vanducng/cflip/internal/config"
+)
+
+// countIssues returns the number of issues with the given severity
+func countIssues(issues []validationIssue, severity string) int {
+	count := 0
+	for _, issue := range issues {
+		if issue.Severity == severity {
+			count++
+		}
+	}
+	return count
+}
+
+func TestValidate(t *testing.T) {
+	setupTestHome(t)
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Provider = glmProvider
+	cfg.SetProviderConfig("spare", config.ProviderConfig{BaseURL: "https://spare.example.com"})
+	if err := config.SaveConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	settingsPath := defaultSettingsPath()
+	if err := generateClaudeSettings(cfg, settingsPath, false, false, true); err != nil {
+		t.Fatal(err)
+	}
+
+	checks, issues := collectValidationIssues(settingsPath)
+	if n := countIssues(issues, severityError); n != 0 {
+		t.Errorf("Expected no errors for a consistent setup, got %+v", issues)
+	}
+	if n := countIssues(issues, severityWarning); n != 1 {
+		t.Errorf("Expected a warning for the keyless inactive provider, got %+v", issues)
+	}
+	if len(checks) != 6 {
+		t.Errorf("Expected config, active provider, three providers and settings checks, got %v", checks)
+	}
+
+	// Drift in the settings file is an error
+	settings, err := LoadSettings(settingsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	settings.Env[config.EnvBaseURL] = "https://elsewhere.example.com"
+	if err := SaveSettings(settingsPath, settings); err != nil {
+		t.Fatal(err)
+	}
+
+	_, issues = collectValidationIssues(settingsPath)
+	if n := countIssues(issues, severityError); n != 1 {
+		t.Errorf("Expected one error for the changed base URL, got %+v", issues)
+	}
+}
+
+func TestValidateMissingAPIKey(t *testing.T) {
+	setupTestHome(t)
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Provider = "keyless"
+	cfg.SetProviderConfig("keyless", config.ProviderConfig{BaseURL: "https://keyless.example.com"})
+	if err := config.SaveConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	_, issues := collectValidationIssues(defaultSettingsPath())
+
+	var found bool
+	for _, issue := range issues {
+		if issue.Check == "provider keyless" && issue.Severity == severityError {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected an error for the active provider without an API key, got %+v", issues)
+	}
+}
+
+func TestValidateDanglingModelMapping(t *testing.T) {
+	tests := []struct {
+		name     string
+		modelMap map[string]string
+	}{
+		{"unknown category", map[string]string{"gigantic": "big-model"}},
+		{"empty model", map[string]string{"sonnet": ""}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupTestHome(t)
+
+			cfg, err := config.LoadConfig()
+			if err != nil {
+				t.Fatal(err)
+			}
+			cfg.SetProviderConfig("proxy", config.ProviderConfig{
+				Token:    "proxy-token",
+				BaseURL:  "https://proxy.example.com",
+				ModelMap: tt.modelMap,
+			})
+			if err := config.SaveConfig(cfg); err != nil {
+				t.Fatal(err)
+			}
+
+			_, issues := collectValidationIssues(defaultSettingsPath())
+
+			var found bool
+			for _, issue := range issues {
+				if issue.Check == "provider proxy" && issue.Severity == severityError {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("Expected an error for the bad model mapping, got %+v", issues)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ResolveAlias returns the provider a name refers to. Provider names win
+// over aliases; a name that is neither is returned unchanged.
+func (c *Config) ResolveAlias(name string) (string, error) {
+	if _, exists := c.Providers[name]; exists {
+		return name, nil
+	}
+
+	owners := c.aliasOwners(name)
+	switch len(owners) {
+	case 0:
+		return name, nil
+	case 1:
+		return owners[0], nil
+	default:
+		return "", fmt.Errorf("alias '%s' is ambiguous: claimed by %s", name, strings.Join(owners, ", "))
+	}
+}
+
+// aliasOwners returns the sorted names of the providers that claim an alias
+func (c *Config) aliasOwners(alias string) []string {
+	var owners []string
+	for name, provider := range c.Providers {
+		for _, a := range provider.Aliases {
+			if a == alias {
+				owners = append(owners, name)
+				break
+			}
+		}
+	}
+	sort.Strings(owners)
+	return owners
+}
+
+// validateAliases checks that the aliases of a provider are valid names that
+// no provider uses and no other provider claims
+func (c *Config) validateAliases(name string) error {
+	for _, alias := range c.Providers[name].Aliases {
+		if !providerNamePattern.MatchString(alias) {
+			return fmt.Errorf("provider '%s': invalid alias '%s'", name, alias)
+		}
+		if _, exists := c.Providers[alias]; exists {
+			return fmt.Errorf("provider '%s': alias '%s' is already a provider name", name, alias)
+		}
+		if owners := c.aliasOwners(alias); len(owners) > 1 {
+			return fmt.Errorf("provider '%s': alias '%s' is claimed by %s", name, alias, strings.Join(owners, ", "))
+		}
+	}
+	return nil
+}
@@ -1,28 +1,113 @@
 package config
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
+	"time"
+)
+
+// Auth modes for the anthropic provider
+const (
+	AuthModeAPI          = "api"
+	AuthModeSubscription = "subscription"
+)
 
-	toml "github.com/BurntSushi/toml"
+// Auth headers a provider token can be sent in
+const (
+	AuthHeaderAuthorization = "authorization"
+	AuthHeaderAPIKey        = "x-api-key"
 )
 
+// providerNamePattern restricts provider names to safe TOML keys and file names
+var providerNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// envVarNamePattern matches names that can be exported as environment variables
+var envVarNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
 // Config represents the configuration structure
 type Config struct {
-	Provider  string                    `toml:"provider"` // "anthropic" or external name
-	Providers map[string]ProviderConfig `toml:"providers"`
+	Provider         string                    `toml:"provider" json:"provider"` // "anthropic" or external name
+	PreviousProvider string                    `toml:"previous_provider,omitempty" json:"previousProvider,omitempty"`
+	Providers        map[string]ProviderConfig `toml:"providers" json:"providers"`
+	Profiles         map[string]Profile        `toml:"profiles,omitempty" json:"profiles,omitempty"`
+	Hooks            Hooks                     `toml:"hooks,omitempty" json:"hooks,omitempty"`
+	Snapshots        SnapshotOptions           `toml:"snapshots,omitempty" json:"snapshots,omitempty"`
+	Validation       ValidationOptions         `toml:"validation,omitempty" json:"validation,omitempty"`
+	SwitchedAt       time.Time                 `toml:"switched_at,omitempty" json:"switchedAt,omitzero"`
+}
+
+// Hooks are shell commands run around a provider switch
+type Hooks struct {
+	// Runs before the switch; a failure aborts it
+	PreSwitch string `toml:"pre_switch,omitempty" json:"preSwitch,omitempty"`
+
+	// Runs after the switch; a failure is only reported
+	PostSwitch string `toml:"post_switch,omitempty" json:"postSwitch,omitempty"`
+}
+
+// SnapshotOptions control the snapshots of Claude settings taken before
+// they are rewritten
+type SnapshotOptions struct {
+	// Never take a snapshot, e.g. in ephemeral containers
+	Disabled bool `toml:"disabled,omitempty" json:"disabled,omitempty"`
+}
+
+// ValidationOptions control how new API keys are checked
+type ValidationOptions struct {
+	// Test every key set with config set-api-key against the provider
+	Auto bool `toml:"auto,omitempty" json:"auto,omitempty"`
 }
 
 // ProviderConfig represents a provider configuration
 type ProviderConfig struct {
 	// For external providers only
-	Token   string `toml:"token,omitempty"`
-	BaseURL string `toml:"base_url,omitempty"`
+	Token   string `toml:"token,omitempty" json:"token,omitempty"`
+	BaseURL string `toml:"base_url,omitempty" json:"baseURL,omitempty"`
 
 	// Optional model mapping (external -> anthropic)
-	ModelMap map[string]string `toml:"model_map,omitempty"`
+	ModelMap map[string]string `toml:"model_map,omitempty" json:"modelMap,omitempty"`
+
+	// Auth mode: "api" or "subscription". With subscription no token is
+	// written and Claude Code signs in with the Claude subscription.
+	AuthMode string `toml:"auth_mode,omitempty" json:"authMode,omitempty"`
+
+	// Optional display name shown in lists and menus
+	DisplayName string `toml:"display_name,omitempty" json:"displayName,omitempty"`
+
+	// Header the token is sent in: "authorization" (default) or "x-api-key"
+	AuthHeader string `toml:"auth_header,omitempty" json:"authHeader,omitempty"`
+
+	// Extra env vars written to Claude settings while the provider is active
+	EnvVars map[string]string `toml:"env,omitempty" json:"env,omitempty"`
+
+	// Other names the provider can be switched to by
+	Aliases []string `toml:"aliases,omitempty" json:"aliases,omitempty"`
+
+	// When cflip test last saw the provider accept its key
+	LastValidated time.Time `toml:"last_validated,omitempty" json:"lastValidated,omitzero"`
+}
+
+// UsesAPIKey returns true if the provider token should be written to Claude settings.
+// An empty auth mode keeps the legacy behavior of using the token whenever one is set.
+func (p ProviderConfig) UsesAPIKey() bool {
+	if p.Token == "" {
+		return false
+	}
+	return p.AuthMode != AuthModeSubscription
+}
+
+// NeedsAPIKey reports whether a provider cannot work without an API key.
+// External providers need one unless they use subscription auth, where
+// Claude Code signs in itself and sends that login to the base URL.
+func (c *Config) NeedsAPIKey(providerName string) bool {
+	return c.IsExternal(providerName) && c.Providers[providerName].AuthMode != AuthModeSubscription
 }
 
 // NewConfig creates a new default configuration
@@ -35,16 +120,58 @@ func NewConfig() *Config {
 	}
 }
 
+// ConfigEnvVar names the environment variable that overrides the config file location
+const ConfigEnvVar = "CFLIP_CONFIG"
+
+// configPathOverride is set from the --config flag and wins over CFLIP_CONFIG
+var configPathOverride string
+
+// SetConfigPath overrides the configuration file location. An empty path
+// restores the default resolution.
+func SetConfigPath(path string) {
+	configPathOverride = path
+}
+
+// ResolveConfigPath returns the path to the configuration file and where it
+// came from: the --config flag, the CFLIP_CONFIG env var or the default
+// location picked by ResolveDirs
+func ResolveConfigPath() (path, source string) {
+	if configPathOverride != "" {
+		return configPathOverride, "--config"
+	}
+	if env := os.Getenv(ConfigEnvVar); env != "" {
+		return env, ConfigEnvVar
+	}
+	configDir, _ := defaultDirs()
+	return filepath.Join(configDir, "config.toml"), "default"
+}
+
 // GetConfigPath returns the path to the configuration file
 func GetConfigPath() string {
-	homeDir, _ := os.UserHomeDir()
-	return filepath.Join(homeDir, ".cflip", "config.toml")
+	path, _ := ResolveConfigPath()
+	return path
+}
+
+// GetConfigBackupPath returns the path of the copy of the previous config
+// kept by SaveConfig. It sits next to an overridden config and in the state
+// directory otherwise.
+func GetConfigBackupPath() string {
+	path, source := ResolveConfigPath()
+	if source != "default" {
+		return path + ".bak"
+	}
+	_, stateDir := defaultDirs()
+	return filepath.Join(stateDir, "config.toml.bak")
 }
 
 // LoadConfig loads the configuration from file
 func LoadConfig() (*Config, error) {
-	configPath := GetConfigPath()
+	return LoadConfigFile(GetConfigPath())
+}
 
+// LoadConfigFile loads the configuration from an explicit path. A missing
+// file yields the default configuration.
+func LoadConfigFile(configPath string) (*Config, error) {
 	// Check if file exists
 	if _, err := os.Stat(configPath); os.IsNotExist(err) {
 		// Return default config if file doesn't exist
@@ -57,30 +184,43 @@ func LoadConfig() (*Config, error) {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
-	config := NewConfig()
-	if err := toml.Unmarshal(data, config); err != nil {
-		return nil, fmt.Errorf("failed to parse config: %w", err)
-	}
-
-	return config, nil
+	return DecodeConfig(data, FormatTOML)
 }
 
 // SaveConfig saves the configuration to file
 func SaveConfig(config *Config) error {
-	configPath := GetConfigPath()
+	return saveConfig(GetConfigPath(), GetConfigBackupPath(), config)
+}
 
+// SaveConfigFile saves the configuration to an explicit path, keeping the
+// previous content next to it with a .bak suffix
+func SaveConfigFile(configPath string, config *Config) error {
+	return saveConfig(configPath, configPath+".bak", config)
+}
+
+// saveConfig writes the configuration to configPath after copying the
+// previous content to backupPath
+func saveConfig(configPath, backupPath string, config *Config) error {
 	// Ensure directory exists
 	if err := os.MkdirAll(filepath.Dir(configPath), 0750); err != nil {
 		return fmt.Errorf("failed to create config directory: %w", err)
 	}
 
 	// Marshal to TOML
-	var buf strings.Builder
-	encoder := toml.NewEncoder(&buf)
-	if err := encoder.Encode(config); err != nil {
-		return fmt.Errorf("failed to marshal config: %w", err)
+	data, err := EncodeConfig(config, FormatTOML)
+	if err != nil {
+		return err
+	}
+
+	// Keep the previous config so a bad save can be undone by hand
+	if previous, err := os.ReadFile(configPath); err == nil && !bytes.Equal(previous, data) {
+		if err := os.MkdirAll(filepath.Dir(backupPath), 0750); err != nil {
+			return fmt.Errorf("failed to create backup directory: %w", err)
+		}
+		if err := os.WriteFile(backupPath, previous, 0600); err != nil {
+			return fmt.Errorf("failed to back up config file: %w", err)
+		}
 	}
-	data := []byte(buf.String())
 
 	// Write to file
 	if err := os.WriteFile(configPath, data, 0600); err != nil {
@@ -99,15 +239,31 @@ func (c *Config) GetActiveProvider() (*ProviderConfig, error) {
 	return &provider, nil
 }
 
-// SetActiveProvider sets the active provider
+// SetActiveProvider sets the active provider, remembering the one it
+// replaces and when the switch happened
 func (c *Config) SetActiveProvider(providerName string) error {
 	if _, exists := c.Providers[providerName]; !exists {
 		return fmt.Errorf("provider '%s' not found", providerName)
 	}
+	if c.Provider != providerName {
+		c.PreviousProvider = c.Provider
+		c.SwitchedAt = time.Now().UTC().Truncate(time.Second)
+	}
 	c.Provider = providerName
 	return nil
 }
 
+// MarkValidated records that a provider accepted its key at the given time
+func (c *Config) MarkValidated(providerName string, at time.Time) error {
+	provider, exists := c.Providers[providerName]
+	if !exists {
+		return fmt.Errorf("provider '%s' not found", providerName)
+	}
+	provider.LastValidated = at.UTC().Truncate(time.Second)
+	c.Providers[providerName] = provider
+	return nil
+}
+
 // SetProviderConfig adds or updates a provider configuration
 func (c *Config) SetProviderConfig(name string, config ProviderConfig) {
 	if c.Providers == nil {
@@ -116,7 +272,169 @@ func (c *Config) SetProviderConfig(name string, config ProviderConfig) {
 	c.Providers[name] = config
 }
 
+// RemoveProvider deletes a provider configuration along with the profiles
+// that use it. The active provider and the built-in anthropic provider
+// cannot be removed.
+func (c *Config) RemoveProvider(name string) error {
+	if _, exists := c.Providers[name]; !exists {
+		return fmt.Errorf("provider '%s' not found", name)
+	}
+	if !c.IsExternal(name) {
+		return fmt.Errorf("provider '%s' is built in and cannot be removed", name)
+	}
+	if c.Provider == name {
+		return fmt.Errorf("provider '%s' is active", name)
+	}
+	delete(c.Providers, name)
+	for _, profile := range c.ProfilesUsing(name) {
+		delete(c.Profiles, profile)
+	}
+	if c.PreviousProvider == name {
+		c.PreviousProvider = ""
+	}
+	return nil
+}
+
+// RenameProvider moves a provider to a new name and updates the active and
+// previous provider and the profiles that refer to it. The built-in
+// anthropic provider cannot be renamed.
+func (c *Config) RenameProvider(oldName, newName string) error {
+	provider, exists := c.Providers[oldName]
+	if !exists {
+		return fmt.Errorf("provider '%s' not found", oldName)
+	}
+	if !c.IsExternal(oldName) || !c.IsExternal(newName) {
+		return fmt.Errorf("the built-in anthropic provider cannot be renamed")
+	}
+	if !providerNamePattern.MatchString(newName) {
+		return fmt.Errorf("invalid provider name '%s' (use lowercase letters, digits, '-' and '_')", newName)
+	}
+	if _, exists := c.Providers[newName]; exists {
+		return fmt.Errorf("provider '%s' already exists", newName)
+	}
+
+	delete(c.Providers, oldName)
+	c.Providers[newName] = provider
+
+	if c.Provider == oldName {
+		c.Provider = newName
+	}
+	if c.PreviousProvider == oldName {
+		c.PreviousProvider = newName
+	}
+	for _, name := range c.ProfilesUsing(oldName) {
+		profile := c.Profiles[name]
+		profile.Provider = newName
+		c.Profiles[name] = profile
+	}
+	return nil
+}
+
+// ClearAPIKey removes the stored token of a provider
+func (c *Config) ClearAPIKey(name string) error {
+	provider, exists := c.Providers[name]
+	if !exists {
+		return fmt.Errorf("provider '%s' not found", name)
+	}
+	provider.Token = ""
+	c.Providers[name] = provider
+	return nil
+}
+
 // IsExternal returns true if the provider is an external provider (not Anthropic)
 func (c *Config) IsExternal(providerName string) bool {
 	return providerName != "anthropic"
 }
+
+// Validate checks the active provider and every provider configuration,
+// returning all problems found
+func (c *Config) Validate() error {
+	var errs []error
+
+	if _, exists := c.Providers[c.Provider]; !exists {
+		errs = append(errs, fmt.Errorf("active provider '%s' not found", c.Provider))
+	}
+
+	names := make([]string, 0, len(c.Providers))
+	for name := range c.Providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := c.ValidateProvider(name); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// ValidateProvider checks that a provider configuration is usable
+func (c *Config) ValidateProvider(name string) error {
+	if !providerNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid provider name '%s' (use lowercase letters, digits, '-' and '_')", name)
+	}
+
+	provider, exists := c.Providers[name]
+	if !exists {
+		return fmt.Errorf("provider '%s' not found", name)
+	}
+
+	if c.IsExternal(name) || provider.BaseURL != "" {
+		if err := ValidateBaseURL(provider.BaseURL); err != nil {
+			return fmt.Errorf("provider '%s': %w", name, err)
+		}
+	}
+
+	switch strings.ToLower(provider.AuthHeader) {
+	case "", AuthHeaderAuthorization, AuthHeaderAPIKey:
+	default:
+		return fmt.Errorf("provider '%s': unsupported auth header '%s' (use %s or %s)",
+			name, provider.AuthHeader, AuthHeaderAuthorization, AuthHeaderAPIKey)
+	}
+
+	switch provider.AuthMode {
+	case "", AuthModeAPI, AuthModeSubscription:
+	default:
+		return fmt.Errorf("provider '%s': invalid auth mode '%s'", name, provider.AuthMode)
+	}
+
+	for category, model := range provider.ModelMap {
+		if _, ok := CategoryEnvKey(category); !ok {
+			return fmt.Errorf("provider '%s': unknown model category '%s' (use %s)",
+				name, category, strings.Join(ModelCategories, ", "))
+		}
+		if strings.TrimSpace(model) == "" {
+			return fmt.Errorf("provider '%s': model for category '%s' is empty", name, category)
+		}
+	}
+
+	for key := range provider.EnvVars {
+		if !envVarNamePattern.MatchString(key) {
+			return fmt.Errorf("provider '%s': invalid env var name '%s'", name, key)
+		}
+		if IsManagedEnvKey(key) {
+			return fmt.Errorf("provider '%s': env var '%s' is set by cflip from the token, base URL and model mappings", name, key)
+		}
+	}
+
+	return c.validateAliases(name)
+}
+
+// ValidateBaseURL checks that a base URL parses and uses http or https
+func ValidateBaseURL(baseURL string) error {
+	if baseURL == "" {
+		return fmt.Errorf("base URL is required")
+	}
+
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return fmt.Errorf("invalid base URL '%s': %w", baseURL, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid base URL '%s' (must be an http or https URL)", baseURL)
+	}
+
+	return nil
+}
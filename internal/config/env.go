@@ -0,0 +1,137 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Claude Code environment variables managed by cflip
+const (
+	EnvAuthToken   = "ANTHROPIC_AUTH_TOKEN"
+	EnvAPIKey      = "ANTHROPIC_API_KEY"
+	EnvBaseURL     = "ANTHROPIC_BASE_URL"
+	EnvHaikuModel  = "ANTHROPIC_DEFAULT_HAIKU_MODEL"
+	EnvSonnetModel = "ANTHROPIC_DEFAULT_SONNET_MODEL"
+	EnvOpusModel   = "ANTHROPIC_DEFAULT_OPUS_MODEL"
+)
+
+// managedEnvKeys are the env vars cflip derives from a provider's token,
+// base URL and model mappings
+var managedEnvKeys = []string{EnvAuthToken, EnvAPIKey, EnvBaseURL, EnvHaikuModel, EnvSonnetModel, EnvOpusModel}
+
+// IsManagedEnvKey reports whether cflip sets key itself, so a provider's
+// extra env vars may not
+func IsManagedEnvKey(key string) bool {
+	return slices.Contains(managedEnvKeys, key)
+}
+
+// ModelCategories lists the model categories in display order
+var ModelCategories = []string{"haiku", "sonnet", "opus"}
+
+// categoryEnvKeys maps a model category to its Claude Code env var
+var categoryEnvKeys = map[string]string{
+	"haiku":  EnvHaikuModel,
+	"sonnet": EnvSonnetModel,
+	"opus":   EnvOpusModel,
+}
+
+// CategoryEnvKey returns the env var for a model category
+func CategoryEnvKey(category string) (string, bool) {
+	key, ok := categoryEnvKeys[category]
+	return key, ok
+}
+
+// OwnedEnvKeys returns every env key cflip may write to Claude settings,
+// including the extra env vars of every provider. These keys are cleared
+// before a provider's env is applied.
+func (c *Config) OwnedEnvKeys() []string {
+	keys := slices.Clone(managedEnvKeys)
+
+	seen := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		seen[key] = true
+	}
+
+	var extra []string
+	for _, provider := range c.Providers {
+		for key := range provider.EnvVars {
+			if !seen[key] {
+				seen[key] = true
+				extra = append(extra, key)
+			}
+		}
+	}
+	sort.Strings(extra)
+
+	return append(keys, extra...)
+}
+
+// TokenEnvKey returns the env var the provider token is written to.
+// Claude Code sends ANTHROPIC_API_KEY as x-api-key and ANTHROPIC_AUTH_TOKEN as a bearer token.
+func (p ProviderConfig) TokenEnvKey() string {
+	if strings.EqualFold(p.AuthHeader, AuthHeaderAPIKey) {
+		return EnvAPIKey
+	}
+	return EnvAuthToken
+}
+
+// APIKeyEnvVar returns the environment variable that overrides a provider's
+// stored API key, for example CFLIP_GLM_API_KEY for glm
+func APIKeyEnvVar(providerName string) string {
+	name := strings.ToUpper(strings.ReplaceAll(providerName, "-", "_"))
+	return "CFLIP_" + name + "_API_KEY"
+}
+
+// ResolveProvider returns a provider's config with the token taken from
+// APIKeyEnvVar when that variable is set. The second result reports whether
+// the token came from the environment. The returned config must not be saved.
+func (c *Config) ResolveProvider(providerName string) (ProviderConfig, bool) {
+	provider := c.Providers[providerName]
+	if token := strings.TrimSpace(os.Getenv(APIKeyEnvVar(providerName))); token != "" {
+		provider.Token = token
+		return provider, true
+	}
+	return provider, false
+}
+
+// GenerateSettingsPreview returns the env vars cflip would write to Claude
+// settings for the given provider, without touching the filesystem
+func (c *Config) GenerateSettingsPreview(providerName string) (map[string]string, error) {
+	env := make(map[string]string)
+	_, exists := c.Providers[providerName]
+	provider, _ := c.ResolveProvider(providerName)
+
+	// Extra env vars go first so they can never override the managed keys
+	for key, value := range provider.EnvVars {
+		env[key] = value
+	}
+
+	if !c.IsExternal(providerName) {
+		// Anthropic uses the Claude Code default endpoint and models,
+		// only the API key is written when it is the active auth mode
+		if provider.UsesAPIKey() {
+			env[provider.TokenEnvKey()] = provider.Token
+		}
+		return env, nil
+	}
+
+	if !exists {
+		return nil, fmt.Errorf("provider '%s' not found", providerName)
+	}
+
+	if provider.UsesAPIKey() {
+		env[provider.TokenEnvKey()] = provider.Token
+	}
+	env[EnvBaseURL] = provider.BaseURL
+
+	for _, category := range ModelCategories {
+		if model, ok := provider.ModelMap[category]; ok && model != "" {
+			env[categoryEnvKeys[category]] = model
+		}
+	}
+
+	return env, nil
+}
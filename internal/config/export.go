@@ -0,0 +1,194 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	toml "github.com/BurntSushi/toml"
+)
+
+// Formats accepted by EncodeConfig and DecodeConfig
+const (
+	FormatTOML = "toml"
+	FormatJSON = "json"
+)
+
+// EncodeConfig serializes a config as TOML or JSON
+func EncodeConfig(config *Config, format string) ([]byte, error) {
+	switch format {
+	case FormatTOML:
+		var buf strings.Builder
+		if err := toml.NewEncoder(&buf).Encode(config); err != nil {
+			return nil, fmt.Errorf("failed to marshal config: %w", err)
+		}
+		return []byte(buf.String()), nil
+	case FormatJSON:
+		data, err := json.MarshalIndent(config, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal config: %w", err)
+		}
+		return append(data, '\n'), nil
+	default:
+		return nil, fmt.Errorf("unsupported config format '%s'", format)
+	}
+}
+
+// DecodeConfig parses a config from TOML or JSON
+func DecodeConfig(data []byte, format string) (*Config, error) {
+	config := NewConfig()
+
+	switch format {
+	case FormatTOML:
+		if err := toml.Unmarshal(data, config); err != nil {
+			return nil, fmt.Errorf("failed to parse config: %w", err)
+		}
+	case FormatJSON:
+		if err := json.Unmarshal(data, config); err != nil {
+			return nil, fmt.Errorf("failed to parse config: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported config format '%s'", format)
+	}
+
+	return config, nil
+}
+
+// Clone returns a deep copy of the config
+func (c *Config) Clone() *Config {
+	clone := &Config{
+		Provider:         c.Provider,
+		PreviousProvider: c.PreviousProvider,
+		Providers:        make(map[string]ProviderConfig, len(c.Providers)),
+		Hooks:            c.Hooks,
+		Snapshots:        c.Snapshots,
+		Validation:       c.Validation,
+		SwitchedAt:       c.SwitchedAt,
+	}
+
+	for name, provider := range c.Providers {
+		provider.ModelMap = copyStringMap(provider.ModelMap)
+		provider.EnvVars = copyStringMap(provider.EnvVars)
+		provider.Aliases = slices.Clone(provider.Aliases)
+		clone.Providers[name] = provider
+	}
+
+	if c.Profiles != nil {
+		clone.Profiles = make(map[string]Profile, len(c.Profiles))
+		for name, profile := range c.Profiles {
+			profile.ModelMap = copyStringMap(profile.ModelMap)
+			clone.Profiles[name] = profile
+		}
+	}
+
+	return clone
+}
+
+// StripTokens removes every provider token
+func (c *Config) StripTokens() {
+	for name, provider := range c.Providers {
+		provider.Token = ""
+		c.Providers[name] = provider
+	}
+}
+
+// KeepTokens gives providers without a token the one they have in previous,
+// so replacing the config with an export made without keys keeps them
+func (c *Config) KeepTokens(previous *Config) {
+	for name, provider := range c.Providers {
+		if old, exists := previous.Providers[name]; exists && provider.Token == "" && old.Token != "" {
+			provider.Token = old.Token
+			c.Providers[name] = provider
+		}
+	}
+}
+
+// DroppedTokens returns the sorted names of the providers that have a token
+// in previous but not in c
+func (c *Config) DroppedTokens(previous *Config) []string {
+	var dropped []string
+	for name, old := range previous.Providers {
+		if old.Token != "" && c.Providers[name].Token == "" {
+			dropped = append(dropped, name)
+		}
+	}
+	slices.Sort(dropped)
+	return dropped
+}
+
+// Merge adds providers, model mappings, env vars and profiles from other.
+// Set fields from other win, but an existing token is never replaced by an
+// empty one. The active provider, hooks, snapshot and validation options
+// are left unchanged, so merging a shared file never installs commands to run.
+func (c *Config) Merge(other *Config) {
+	for name, incoming := range other.Providers {
+		existing, exists := c.Providers[name]
+		if !exists {
+			incoming.ModelMap = copyStringMap(incoming.ModelMap)
+			incoming.EnvVars = copyStringMap(incoming.EnvVars)
+			incoming.Aliases = slices.Clone(incoming.Aliases)
+			c.SetProviderConfig(name, incoming)
+			continue
+		}
+
+		if incoming.Token != "" {
+			existing.Token = incoming.Token
+		}
+		if incoming.BaseURL != "" {
+			existing.BaseURL = incoming.BaseURL
+		}
+		if incoming.AuthMode != "" {
+			existing.AuthMode = incoming.AuthMode
+		}
+		if incoming.DisplayName != "" {
+			existing.DisplayName = incoming.DisplayName
+		}
+		if incoming.AuthHeader != "" {
+			existing.AuthHeader = incoming.AuthHeader
+		}
+		existing.ModelMap = mergeStringMaps(existing.ModelMap, incoming.ModelMap)
+		existing.EnvVars = mergeStringMaps(existing.EnvVars, incoming.EnvVars)
+		if len(incoming.Aliases) > 0 {
+			existing.Aliases = slices.Clone(incoming.Aliases)
+		}
+		if incoming.LastValidated.After(existing.LastValidated) {
+			existing.LastValidated = incoming.LastValidated
+		}
+		c.SetProviderConfig(name, existing)
+	}
+
+	for name, profile := range other.Profiles {
+		if c.Profiles == nil {
+			c.Profiles = make(map[string]Profile)
+		}
+		profile.ModelMap = copyStringMap(profile.ModelMap)
+		c.Profiles[name] = profile
+	}
+}
+
+// copyStringMap returns a copy of m, or nil when m is empty
+func copyStringMap(m map[string]string) map[string]string {
+	if len(m) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(m))
+	for key, value := range m {
+		out[key] = value
+	}
+	return out
+}
+
+// mergeStringMaps returns base with the entries of overlay added on top
+func mergeStringMaps(base, overlay map[string]string) map[string]string {
+	if len(overlay) == 0 {
+		return base
+	}
+	if base == nil {
+		base = make(map[string]string, len(overlay))
+	}
+	for key, value := range overlay {
+		base[key] = value
+	}
+	return base
+}
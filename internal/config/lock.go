@@ -0,0 +1,61 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// LockTimeout is how long LockConfig waits for another process to release the lock
+const LockTimeout = 10 * time.Second
+
+// lockRetryInterval is the pause between attempts to take a held lock
+const lockRetryInterval = 50 * time.Millisecond
+
+// errLockHeld is returned by tryLockFile when another process holds the lock
+var errLockHeld = errors.New("lock held")
+
+// GetLockPath returns the path of the lock file guarding the cflip config
+// and the Claude settings it generates
+func GetLockPath() string {
+	return GetConfigPath() + ".lock"
+}
+
+// LockConfig takes an exclusive advisory lock for a read-modify-write of the
+// cflip config and Claude settings, waiting up to timeout. The returned
+// function releases it.
+func LockConfig(timeout time.Duration) (func(), error) {
+	path := GetLockPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return nil, fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		err := tryLockFile(f)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, errLockHeld) {
+			f.Close()
+			return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+		}
+		if time.Now().After(deadline) {
+			f.Close()
+			return nil, fmt.Errorf("another cflip process holds the lock on %s; try again when it finishes", path)
+		}
+		time.Sleep(lockRetryInterval)
+	}
+
+	return func() {
+		_ = unlockFile(f)
+		f.Close()
+	}, nil
+}
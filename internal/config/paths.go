@@ -0,0 +1,112 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// LegacyDirName is the directory under HOME that cflip used before XDG support
+const LegacyDirName = ".cflip"
+
+// MovedFileName is the pointer file migrate-paths leaves in the legacy directory
+const MovedFileName = "MOVED"
+
+// ResolveDirs returns the directories cflip keeps its config and its state,
+// such as config backups, in. An existing ~/.cflip/config.toml keeps
+// working; otherwise Linux and other Unix systems use the XDG base
+// directories and macOS and Windows keep using ~/.cflip.
+func ResolveDirs(home, xdgConfigHome, xdgStateHome, goos string, exists func(string) bool) (configDir, stateDir string) {
+	legacyDir := filepath.Join(home, LegacyDirName)
+	if exists(filepath.Join(legacyDir, "config.toml")) || goos == "darwin" || goos == "windows" {
+		return legacyDir, legacyDir
+	}
+
+	if xdgConfigHome == "" {
+		xdgConfigHome = filepath.Join(home, ".config")
+	}
+	if xdgStateHome == "" {
+		xdgStateHome = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(xdgConfigHome, "cflip"), filepath.Join(xdgStateHome, "cflip")
+}
+
+// defaultDirs resolves the config and state directories for this system
+func defaultDirs() (configDir, stateDir string) {
+	homeDir, _ := os.UserHomeDir()
+	return ResolveDirs(homeDir, os.Getenv("XDG_CONFIG_HOME"), os.Getenv("XDG_STATE_HOME"), runtime.GOOS, fileExists)
+}
+
+// fileExists reports whether path exists
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// MigrateLegacyDir moves the config and its backup out of ~/.cflip into the
+// XDG base directories and leaves a MOVED file behind that points at them.
+// It returns the new config path and state directory.
+func MigrateLegacyDir() (configPath, stateDir string, err error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to find home directory: %w", err)
+	}
+
+	legacyDir := filepath.Join(homeDir, LegacyDirName)
+	legacyConfig := filepath.Join(legacyDir, "config.toml")
+	if !fileExists(legacyConfig) {
+		return "", "", fmt.Errorf("no config to migrate in %s", legacyDir)
+	}
+
+	noLegacy := func(string) bool { return false }
+	configDir, stateDir := ResolveDirs(homeDir, os.Getenv("XDG_CONFIG_HOME"), os.Getenv("XDG_STATE_HOME"), runtime.GOOS, noLegacy)
+	if configDir == legacyDir {
+		return "", "", fmt.Errorf("cflip keeps using %s on %s", legacyDir, runtime.GOOS)
+	}
+
+	configPath = filepath.Join(configDir, "config.toml")
+	if fileExists(configPath) {
+		return "", "", fmt.Errorf("%s already exists; remove it or merge it by hand first", configPath)
+	}
+
+	if err := os.MkdirAll(configDir, 0750); err != nil {
+		return "", "", fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := moveFile(legacyConfig, configPath); err != nil {
+		return "", "", fmt.Errorf("failed to move config file: %w", err)
+	}
+
+	if legacyBackup := legacyConfig + ".bak"; fileExists(legacyBackup) {
+		if err := os.MkdirAll(stateDir, 0750); err != nil {
+			return "", "", fmt.Errorf("failed to create state directory: %w", err)
+		}
+		if err := moveFile(legacyBackup, filepath.Join(stateDir, "config.toml.bak")); err != nil {
+			return "", "", fmt.Errorf("failed to move config backup: %w", err)
+		}
+	}
+
+	pointer := fmt.Sprintf("cflip now keeps its config in %s and its backups in %s.\n"+
+		"This directory is no longer used and can be removed.\n", configPath, stateDir)
+	if err := os.WriteFile(filepath.Join(legacyDir, MovedFileName), []byte(pointer), 0600); err != nil {
+		return "", "", fmt.Errorf("failed to write %s: %w", MovedFileName, err)
+	}
+
+	return configPath, stateDir, nil
+}
+
+// moveFile renames src to dst, copying when they are on different file systems
+func moveFile(src, dst string) error {
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(dst, data, 0600); err != nil {
+		return err
+	}
+	return os.Remove(src)
+}
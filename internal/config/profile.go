@@ -0,0 +1,94 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Profile is a named provider and model selection that can be activated in one step
+type Profile struct {
+	Provider string            `toml:"provider" json:"provider"`
+	AuthMode string            `toml:"auth_mode,omitempty" json:"authMode,omitempty"`
+	ModelMap map[string]string `toml:"model_map,omitempty" json:"modelMap,omitempty"`
+}
+
+// SaveProfile stores the active provider, its auth mode and model mappings under a name
+func (c *Config) SaveProfile(name string) error {
+	if !providerNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid profile name '%s' (use lowercase letters, digits, '-' and '_')", name)
+	}
+
+	provider, exists := c.Providers[c.Provider]
+	if !exists {
+		return fmt.Errorf("active provider '%s' not found", c.Provider)
+	}
+
+	profile := Profile{Provider: c.Provider, AuthMode: provider.AuthMode}
+	if len(provider.ModelMap) > 0 {
+		profile.ModelMap = make(map[string]string, len(provider.ModelMap))
+		for category, model := range provider.ModelMap {
+			profile.ModelMap[category] = model
+		}
+	}
+
+	if c.Profiles == nil {
+		c.Profiles = make(map[string]Profile)
+	}
+	c.Profiles[name] = profile
+	return nil
+}
+
+// ApplyProfile activates a profile's provider with the profile's auth mode and model mappings
+func (c *Config) ApplyProfile(name string) error {
+	profile, exists := c.Profiles[name]
+	if !exists {
+		return fmt.Errorf("profile '%s' not found", name)
+	}
+
+	provider, exists := c.Providers[profile.Provider]
+	if !exists {
+		return fmt.Errorf("profile '%s' uses provider '%s', which is not configured", name, profile.Provider)
+	}
+
+	provider.AuthMode = profile.AuthMode
+	provider.ModelMap = nil
+	if len(profile.ModelMap) > 0 {
+		provider.ModelMap = make(map[string]string, len(profile.ModelMap))
+		for category, model := range profile.ModelMap {
+			provider.ModelMap[category] = model
+		}
+	}
+	c.SetProviderConfig(profile.Provider, provider)
+
+	return c.SetActiveProvider(profile.Provider)
+}
+
+// DeleteProfile removes a saved profile
+func (c *Config) DeleteProfile(name string) error {
+	if _, exists := c.Profiles[name]; !exists {
+		return fmt.Errorf("profile '%s' not found", name)
+	}
+	delete(c.Profiles, name)
+	return nil
+}
+
+// ProfileNames returns the saved profile names in sorted order
+func (c *Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ProfilesUsing returns the sorted names of the profiles that activate a provider
+func (c *Config) ProfilesUsing(provider string) []string {
+	var names []string
+	for _, name := range c.ProfileNames() {
+		if c.Profiles[name].Provider == provider {
+			names = append(names, name)
+		}
+	}
+	return names
+}
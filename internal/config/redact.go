@@ -0,0 +1,19 @@
+package config
+
+import "strings"
+
+// Characters Redact keeps at each end of a token
+const (
+	redactHead = 4
+	redactTail = 2
+)
+
+// Redact masks a token for display, keeping the first 4 and last 2
+// characters. Tokens too short to keep most of them hidden are masked
+// entirely. Anything that prints a token must go through Redact.
+func Redact(token string) string {
+	if len(token) < 2*(redactHead+redactTail) {
+		return strings.Repeat("*", len(token))
+	}
+	return token[:redactHead] + strings.Repeat("*", len(token)-redactHead-redactTail) + token[len(token)-redactTail:]
+}